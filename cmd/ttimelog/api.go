@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/api"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// apiRequestMsg carries a request of the socket API into Update, which owns
+// the entries, and its reply back out
+type apiRequestMsg struct {
+	method string
+	text   string
+	reply  chan apiReply
+}
+
+type apiReply struct {
+	entry   timelog.ExportedEntry
+	entries []timelog.ExportedEntry
+	stats   api.Stats
+	err     error
+}
+
+// tuiBackend answers API requests from the running TUI, so added entries
+// show up immediately
+type tuiBackend struct {
+	program *tea.Program
+}
+
+func (b *tuiBackend) request(ctx context.Context, method, text string) (apiReply, error) {
+	// buffered, Update must not block when the caller gave up
+	reply := make(chan apiReply, 1)
+	b.program.Send(apiRequestMsg{method: method, text: text, reply: reply})
+	select {
+	case r := <-reply:
+		return r, r.err
+	case <-ctx.Done():
+		return apiReply{}, ctx.Err()
+	}
+}
+
+func (b *tuiBackend) Add(ctx context.Context, text string) (timelog.ExportedEntry, error) {
+	r, err := b.request(ctx, api.MethodAdd, text)
+	return r.entry, err
+}
+
+func (b *tuiBackend) Stats(ctx context.Context) (api.Stats, error) {
+	r, err := b.request(ctx, api.MethodStats, "")
+	return r.stats, err
+}
+
+func (b *tuiBackend) Today(ctx context.Context) ([]timelog.ExportedEntry, error) {
+	r, err := b.request(ctx, api.MethodToday, "")
+	return r.entries, err
+}
+
+func (m *model) handleAPIRequest(msg apiRequestMsg) {
+	var r apiReply
+	switch msg.method {
+	case api.MethodAdd:
+		entry := m.logEntry(msg.text)
+		r.entry = timelog.ExportEntry(entry)
+		if entry.Unsaved {
+			r.err = errors.New("logged but not saved yet, see ttimelog")
+		}
+	case api.MethodStats:
+		r.stats = m.apiStats(time.Now())
+	case api.MethodToday:
+		r.entries = []timelog.ExportedEntry{}
+		for _, entry := range m.todayEntries(time.Now()) {
+			r.entries = append(r.entries, timelog.ExportEntry(entry))
+		}
+	default:
+		r.err = fmt.Errorf("unknown method %q", msg.method)
+	}
+	msg.reply <- r
+}
+
+func (m model) todayEntries(now time.Time) []timelog.Entry {
+	today := timelog.DateKey(now)
+	var entries []timelog.Entry
+	for _, entry := range m.entries {
+		if timelog.DateKey(entry.EndTime) == today {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (m model) apiStats(now time.Time) api.Stats {
+	day := timelog.NewDayStatus(m.todayEntries(now), now, time.Duration(targetDailyHours*float64(time.Hour)))
+	stats := api.Stats{
+		Today:     timelog.ExportTotals(m.statsCollection.Daily),
+		Week:      timelog.ExportTotals(m.statsCollection.Weekly),
+		Month:     timelog.ExportTotals(m.statsCollection.Monthly),
+		Remaining: int(day.Remaining.Minutes()),
+		Last:      day.Last,
+		Elapsed:   int(day.Elapsed.Minutes()),
+	}
+	if !day.Arrived.IsZero() {
+		stats.Arrived = &day.Arrived
+		stats.Leave = &day.Leave
+	}
+	return stats
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type chartKind int
+
+const (
+	chartDays chartKind = iota
+	chartProjects
+)
+
+// charts renders horizontal bar charts of hours per day against the daily
+// target, and hours per project or category, for a week or a month.
+type charts struct {
+	entries     []timelog.Entry
+	kind        chartKind
+	period      period
+	byCategory  bool
+	width       int
+	height      int
+	targetHours float64
+	isHoliday   func(time.Time) bool
+	// help is the one line key summary shown below the chart
+	help string
+}
+
+func newCharts(entries []timelog.Entry, now time.Time, targetHours float64, isHoliday func(time.Time) bool) charts {
+	return charts{
+		entries:     entries,
+		period:      newPeriod(periodWeek, now),
+		targetHours: targetHours,
+		isHoliday:   isHoliday,
+	}
+}
+
+func (c *charts) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+}
+
+func (c *charts) ToggleKind() {
+	if c.kind == chartDays {
+		c.kind = chartProjects
+	} else {
+		c.kind = chartDays
+	}
+}
+
+func (c *charts) SetPeriod(kind periodKind) {
+	c.period.SetKind(kind)
+}
+
+func (c *charts) ToggleCategory() {
+	c.byCategory = !c.byCategory
+}
+
+// Shift moves the shown period backwards (negative) or forwards in time
+func (c *charts) Shift(n int) {
+	c.period.Shift(n)
+}
+
+func isWorkingDay(day time.Time, isHoliday func(time.Time) bool) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return isHoliday == nil || !isHoliday(day)
+}
+
+// bar draws value scaled to width cells, with the part up to target that
+// was not reached drawn dimmed.
+func bar(value, target, scale float64, width int, color lipgloss.Color) string {
+	if scale <= 0 {
+		return strings.Repeat(" ", width)
+	}
+	filled := min(int(value/scale*float64(width)+0.5), width)
+	missing := max(min(int(target/scale*float64(width)+0.5), width)-filled, 0)
+
+	return lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(theme.Active().Muted).Render(strings.Repeat("░", missing)) +
+		strings.Repeat(" ", width-filled-missing)
+}
+
+func (c charts) dayRows(maxRows int) []string {
+	from, to := c.period.Range()
+	today := timelog.StartOfDay(time.Now())
+	days := timelog.SummarizeDays(c.entries)
+
+	scale := c.targetHours
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		scale = max(scale, days[timelog.DateKey(day)].Work.Hours())
+	}
+
+	const labelWidth = len("Mon 02 ")
+	const valueWidth = len(" 00h00m / 0h")
+	barWidth := max(c.width-labelWidth-valueWidth, 1)
+
+	rows := make([]string, 0)
+	for day := from; day.Before(to) && !day.After(today); day = day.AddDate(0, 0, 1) {
+		work := days[timelog.DateKey(day)].Work.Hours()
+		target := 0.0
+		if isWorkingDay(day, c.isHoliday) {
+			target = c.targetHours
+		}
+
+		t := theme.Active()
+		color := t.Accent
+		switch {
+		case work > c.targetHours*1.25:
+			color = t.Danger
+		case target > 0 && work >= target:
+			color = t.Success
+		}
+
+		value := timelog.FormatStatDuration(days[timelog.DateKey(day)].Work)
+		if target > 0 {
+			value += fmt.Sprintf(" / %gh", target)
+		}
+		rows = append(rows, day.Format("Mon 02 ")+bar(work, target, scale, barWidth, color)+" "+value)
+	}
+
+	if len(rows) > maxRows {
+		// keep the most recent days
+		rows = rows[len(rows)-maxRows:]
+	}
+	return rows
+}
+
+func (c charts) projectRows(maxRows int) []string {
+	from, to := c.period.Range()
+	key := timelog.ProjectOf
+	if c.byCategory {
+		key = timelog.CategoryOf
+	}
+	totals := timelog.SummarizeBy(timelog.EntriesBetween(c.entries, from, to), key)
+	if len(totals) == 0 {
+		return []string{"No work logged"}
+	}
+
+	var sum time.Duration
+	for _, total := range totals {
+		sum += total.Duration
+	}
+
+	labelWidth := max(c.width/3, 8)
+	const valueWidth = len(" 000h00m 100%")
+	barWidth := max(c.width-labelWidth-valueWidth-1, 1)
+	labelStyle := lipgloss.NewStyle().Width(labelWidth).MaxWidth(labelWidth)
+
+	rows := make([]string, 0, len(totals))
+	for i, total := range totals {
+		if i == maxRows-1 && len(totals) > maxRows {
+			rows = append(rows, fmt.Sprintf("… %d more", len(totals)-i))
+			break
+		}
+		label := labelStyle.Render(truncateLeft(total.Name, labelWidth-1))
+		percent := total.Duration.Hours() / sum.Hours() * 100
+		rows = append(rows, fmt.Sprintf("%s %s %s %3.0f%%", label,
+			bar(total.Duration.Hours(), 0, totals[0].Duration.Hours(), barWidth, theme.Active().Accent),
+			timelog.FormatStatDuration(total.Duration), percent))
+	}
+	return rows
+}
+
+// truncateLeft keeps the end of long project paths, which is the most specific part
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width || width < 2 {
+		return s
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+func (c charts) View() string {
+	heading := "Hours per day"
+	if c.kind == chartProjects {
+		heading = "Hours per project"
+		if c.byCategory {
+			heading = "Hours per category"
+		}
+	}
+
+	// heading, blank line and help line
+	maxRows := max(c.height-4, 1)
+	var rows []string
+	if c.kind == chartDays {
+		rows = c.dayRows(maxRows)
+	} else {
+		rows = c.projectRows(maxRows)
+	}
+
+	return paneView(heading+" — "+c.period.Title(), rows, 0, c.height, c.help)
+}
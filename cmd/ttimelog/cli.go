@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/api"
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/editor"
+	"github.com/Rash419/ttimelog/internal/hooks"
+	"github.com/Rash419/ttimelog/internal/report"
+	"github.com/Rash419/ttimelog/internal/source"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/Rash419/ttimelog/internal/treeview"
+)
+
+// exit codes of the subcommands
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// cli runs the non-interactive subcommands, output goes to stdout and
+// diagnostics to stderr
+type cli struct {
+	appConfig       *config.AppConfig
+	timeLogFilePath string
+	stdout          io.Writer
+	stderr          io.Writer
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"add", `"text"`, "log a task, like typing it in the input", (*cli).add},
+	{"status", "[--line [--format TEMPLATE]] [--json]", "show the totals, or a one-line status for status bars", (*cli).status},
+	{"report", "[--day|--week|--month] [--date YYYY-MM-DD] [--category] [--template NAME]", "sum the work per project", (*cli).report},
+	{"export", "[--format csv|json|timelog] [--from YYYY-MM-DD] [--to YYYY-MM-DD]", "write entries to stdout", (*cli).export},
+	{"standup", "[--copy]", "summarize the previous working day and today", (*cli).standup},
+	{"edit", "", "open ttimelog.txt in $VISUAL/$EDITOR", (*cli).edit},
+	{"fetch-projects", "", "download the project lists of all sources", (*cli).fetchProjects},
+	{"check", "[--from YYYY-MM-DD] [--to YYYY-MM-DD]", "list entries whose project is not in the project list", (*cli).check},
+	{"git-hook", "install [--force] [DIR] | post-commit", "log or suggest commits, install the hook in a repository", (*cli).gitHook},
+	{"serve", "[--listen ADDRESS]", "serve the interface over SSH without a local one", (*cli).serve},
+}
+
+// usageError is a wrong command line, reported with exit code 2. An empty
+// message means the flag package printed it already.
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ttimelog [command]")
+	fmt.Fprintln(w, "\nWithout a command the interactive interface is started.")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w, "\nRun ttimelog <command> -h for the options of a command.")
+}
+
+// runCommand runs the subcommand args[0] and returns the exit code
+func runCommand(appConfig *config.AppConfig, timeLogFilePath string, args []string) int {
+	c := &cli{appConfig: appConfig, timeLogFilePath: timeLogFilePath, stdout: os.Stdout, stderr: os.Stderr}
+	return c.run(args)
+}
+
+// run runs the subcommand args[0], writing to the writers of c
+func (c *cli) run(args []string) int {
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		printUsage(c.stdout)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(c, args[1:])
+		var usageErr usageError
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.As(err, &usageErr):
+			if usageErr.message != "" {
+				fmt.Fprintf(c.stderr, "ttimelog %s: %s\nUsage: ttimelog %s %s\n", name, usageErr.message, cmd.name, cmd.args)
+			}
+			return exitUsage
+		default:
+			slog.Error("Command failed", "command", name, "error", err)
+			fmt.Fprintf(c.stderr, "ttimelog %s: %v\n", name, err)
+			return exitFailure
+		}
+	}
+
+	fmt.Fprintf(c.stderr, "ttimelog: unknown command %q\n\n", name)
+	printUsage(c.stderr)
+	return exitUsage
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("ttimelog "+name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// parse parses the flags, failures are usage errors
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{}
+	}
+	return nil
+}
+
+func parseDate(name, value string) (time.Time, error) {
+	date, err := time.ParseInLocation(timelog.DateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, usageError{fmt.Sprintf("--%s %q is not a YYYY-MM-DD date", name, value)}
+	}
+	return date, nil
+}
+
+func (c *cli) loadEntries() ([]timelog.Entry, timelog.StatsCollection, bool, error) {
+	entries, stats, handledArrived, err := timelog.LoadEntries(c.timeLogFilePath)
+	if err != nil {
+		return nil, stats, false, fmt.Errorf("failed to read %s: %w", config.TimeLogFilename, err)
+	}
+	return entries, stats, handledArrived, nil
+}
+
+func (c *cli) add(args []string) error {
+	flags := c.flagSet("add")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	description := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if description == "" {
+		return usageError{"nothing to log"}
+	}
+	return c.logEntry(description)
+}
+
+// logEntry logs description like typing it in the input
+func (c *cli) logEntry(description string) error {
+	// a running TUI shows the entry right away and keeps its state
+	if client, err := api.Dial(filepath.Join(filepath.Dir(c.timeLogFilePath), config.SocketFile)); err == nil {
+		defer client.Close()
+		exported, err := client.Add(context.Background(), description)
+		if err != nil {
+			return fmt.Errorf("failed to add entry: %w", err)
+		}
+		c.printAdded(exported.End, description, time.Duration(exported.Minutes)*time.Minute)
+		return nil
+	}
+
+	entries, _, handledArrived, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	entry, startsDay := timelog.LogEntry(entries, handledArrived, description, time.Now())
+	journal := timelog.NewJournal(c.timeLogFilePath, 1)
+	if err := journal.Apply(timelog.Operation{Kind: timelog.OpAppend, Text: timelog.FormatEntry(entry, startsDay)}); err != nil {
+		return fmt.Errorf("failed to add entry: %w", err)
+	}
+	c.printAdded(entry.EndTime, entry.Description, entry.Duration)
+
+	// a running TUI fires the hooks of the entries it adds
+	runner := hooks.FromConfig(c.appConfig)
+	runner.EntryLogged(entries, entry, startsDay, time.Duration(targetDailyHours*float64(time.Hour)))
+	runner.Wait()
+	return nil
+}
+
+func (c *cli) printAdded(end time.Time, description string, duration time.Duration) {
+	if duration > 0 {
+		fmt.Fprintf(c.stdout, "%s %s (%s)\n", end.Format("15:04"), description, timelog.FormatStatDuration(duration))
+	} else {
+		fmt.Fprintf(c.stdout, "%s %s\n", end.Format("15:04"), description)
+	}
+}
+
+func (c *cli) status(args []string) error {
+	flags := c.flagSet("status")
+	line := flags.Bool("line", false, "print a one-line status of today")
+	format := flags.String("format", "", "Go template of the one-line status, implies --line")
+	asJSON := flags.Bool("json", false, "print the one-line status as JSON for waybar and i3blocks")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *line || *format != "" || *asJSON {
+		return c.statusLine(*format, *asJSON)
+	}
+
+	entries, stats, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, period := range []struct {
+		name  string
+		stats timelog.Stats
+	}{
+		{"Today", stats.Daily},
+		{"Week", stats.Weekly},
+		{"Month", stats.Monthly},
+	} {
+		fmt.Fprintf(tw, "%s\t%s work\t%s slack\n", period.name,
+			timelog.FormatStatDuration(period.stats.Work), timelog.FormatStatDuration(period.stats.Slack))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !stats.ArrivedTime.IsZero() {
+		fmt.Fprintf(c.stdout, "Arrived at %s\n", stats.ArrivedTime.Format("15:04"))
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		fmt.Fprintf(c.stdout, "Last entry %s ago: %s %s\n", timelog.FormatStatDuration(time.Since(last.EndTime)),
+			last.EndTime.Format("2006-01-02 15:04"), last.Description)
+	}
+	return nil
+}
+
+func (c *cli) report(args []string) error {
+	flags := c.flagSet("report")
+	day := flags.Bool("day", false, "report a single day")
+	week := flags.Bool("week", false, "report a week (the default)")
+	month := flags.Bool("month", false, "report a month")
+	date := flags.String("date", "", "a day of the reported period, today by default")
+	byCategory := flags.Bool("category", false, "sum per top level category instead of project")
+	templateName := flags.String("template", "", "render with a template of ~/.ttimelog/templates or a bundled one: markdown, email, org")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	anchor := timelog.StartOfDay(time.Now())
+	if *date != "" {
+		var err error
+		if anchor, err = parseDate("date", *date); err != nil {
+			return err
+		}
+	}
+
+	var from, to time.Time
+	var title string
+	switch {
+	case *day && (*week || *month), *week && *month:
+		return usageError{"use only one of --day, --week and --month"}
+	case *day:
+		from, to = anchor, anchor.AddDate(0, 0, 1)
+		title = from.Format("Monday 02 January 2006")
+	case *month:
+		from, to = timelog.MonthRange(anchor)
+		title = from.Format("January 2006")
+	default:
+		from, to = timelog.WeekRange(anchor)
+		_, weekNumber := from.ISOWeek()
+		title = fmt.Sprintf("Week %d (%s - %s)", weekNumber, from.Format("02 Jan"), to.AddDate(0, 0, -1).Format("02 Jan 2006"))
+	}
+
+	key := timelog.ProjectOf
+	if *byCategory {
+		key = timelog.CategoryOf
+	}
+	if *templateName != "" {
+		return c.renderReport(*templateName, from, to, report.Options{
+			Title:     title,
+			Target:    time.Duration(targetDailyHours * float64(time.Hour)),
+			IsHoliday: c.appConfig.IsHoliday,
+			Key:       key,
+		})
+	}
+
+	entries, _, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	entries = timelog.EntriesBetween(entries, from, to)
+	var stats timelog.Stats
+	for _, dayStats := range timelog.SummarizeDays(entries) {
+		stats.Work += dayStats.Work
+		stats.Slack += dayStats.Slack
+	}
+
+	fmt.Fprintln(c.stdout, title)
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	for _, total := range timelog.SummarizeBy(entries, key) {
+		fmt.Fprintf(tw, "  %s\t%s\t\n", total.Name, timelog.FormatStatDuration(total.Duration))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.stdout, "Total %s work, %s slack\n",
+		timelog.FormatStatDuration(stats.Work), timelog.FormatStatDuration(stats.Slack))
+	return err
+}
+
+// renderReport renders the report of [from, to) with a template
+func (c *cli) renderReport(name string, from, to time.Time, options report.Options) error {
+	tmpl, err := report.Load(filepath.Join(c.appConfig.TimeLogDirPath, config.TemplatesDirname), name)
+	if err != nil {
+		return err
+	}
+	entries, _, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	return report.Render(c.stdout, tmpl, report.New(entries, from, to, options, time.Now()))
+}
+
+func (c *cli) export(args []string) error {
+	flags := c.flagSet("export")
+	format := flags.String("format", timelog.ExportCSV, "output format: "+strings.Join(timelog.ExportFormats, ", "))
+	fromFlag := flags.String("from", "", "first day to export, the beginning by default")
+	toFlag := flags.String("to", "", "last day to export, today by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if !slices.Contains(timelog.ExportFormats, *format) {
+		return usageError{fmt.Sprintf("unknown format %q, use %s", *format, strings.Join(timelog.ExportFormats, ", "))}
+	}
+	from, to, err := parseRange(*fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	entries, _, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	return timelog.Export(c.stdout, *format, timelog.EntriesBetween(entries, from, to))
+}
+
+// parseRange parses the inclusive --from and --to days into [from, to),
+// from the beginning until today when not given
+func parseRange(fromFlag, toFlag string) (time.Time, time.Time, error) {
+	var from time.Time
+	to := timelog.StartOfDay(time.Now()).AddDate(0, 0, 1)
+	var err error
+	if fromFlag != "" {
+		if from, err = parseDate("from", fromFlag); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if toFlag != "" {
+		if to, err = parseDate("to", toFlag); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		// --to is inclusive
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, usageError{"--from is after --to"}
+	}
+	return from, to, nil
+}
+
+// check lists the entries whose project is missing from the project list,
+// failing when there are any
+func (c *cli) check(args []string) error {
+	flags := c.flagSet("check")
+	fromFlag := flags.String("from", "", "first day to check, the beginning by default")
+	toFlag := flags.String("to", "", "last day to check, today by default")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	from, to, err := parseRange(*fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := c.appConfig.ResolveAuthHeader(ctx); err != nil {
+		return err
+	}
+	// the cached lists, unless they are stale
+	root, loadErr := source.LoadAll(ctx, source.FromConfig(c.appConfig), false)
+	index := chrono.NewProjectIndex(root)
+	if index.Empty() {
+		return errors.Join(errors.New("no project list to check against"), loadErr)
+	}
+	if loadErr != nil {
+		fmt.Fprintf(c.stderr, "Project list incomplete, checking against the loaded part only: %v\n", loadErr)
+	}
+
+	entries, _, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	invalid := chrono.CheckEntries(entries, from, to, index)
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, item := range invalid {
+		_, description, _ := strings.Cut(item.Entry.Description, ": ")
+		fmt.Fprintf(tw, "%s\t%s (%s)\t%s\n", item.Entry.EndTime.Format("2006-01-02 15:04"), item.Project, item.Status, strings.TrimSpace(description))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d entries with unknown projects", len(invalid))
+	}
+	fmt.Fprintln(c.stdout, "All projects exist in the project list")
+	return nil
+}
+
+func (c *cli) edit(args []string) error {
+	flags := c.flagSet("edit")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	cmd, err := editor.Command(c.timeLogFilePath, 0)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (c *cli) fetchProjects(args []string) error {
+	flags := c.flagSet("fetch-projects")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := c.appConfig.ResolveAuthHeader(ctx); err != nil {
+		return err
+	}
+	root, loadErr := source.LoadAll(ctx, source.FromConfig(c.appConfig), true)
+	fmt.Fprintf(c.stdout, "%d projects\n", countLeaves(root))
+	return loadErr
+}
+
+func countLeaves(node *treeview.TreeNode) int {
+	if node == nil {
+		return 0
+	}
+	if len(node.Children) == 0 {
+		if node.Path == "" {
+			// an empty root
+			return 0
+		}
+		return 1
+	}
+	count := 0
+	for _, child := range node.Children {
+		count += countLeaves(child)
+	}
+	return count
+}
+
+const defaultStatusLineFormat = `{{if .Last}}{{truncate 30 .Last}} +{{duration .Elapsed}} · {{end}}{{duration .Work}}/{{duration .Target}}{{if not .Leave.IsZero}} · leave {{clock .Leave}}{{end}}`
+
+var statusLineFuncs = template.FuncMap{
+	"duration": timelog.FormatStatDuration,
+	"clock": func(t time.Time) string {
+		if t.IsZero() {
+			return "--:--"
+		}
+		return t.Format("15:04")
+	},
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:max(n-1, 0)]) + "…"
+		}
+		return s
+	},
+}
+
+// statusBarOutput is the JSON of custom waybar modules, i3blocks reads the
+// same with format=json
+type statusBarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+// statusLine prints today's status with the template format, falling back
+// to [statusline] format and the built-in one. Only today's part of the
+// timelog is read to keep it fast enough for frequent refreshes.
+func (c *cli) statusLine(format string, asJSON bool) error {
+	if format == "" {
+		format = c.appConfig.StatusLine.Format
+	}
+	if format == "" {
+		format = defaultStatusLineFormat
+	}
+	tmpl, err := template.New("statusline").Funcs(statusLineFuncs).Parse(format)
+	if err != nil {
+		return usageError{fmt.Sprintf("invalid format: %v", err)}
+	}
+
+	now := time.Now()
+	entries, err := timelog.LoadDay(c.timeLogFilePath, now)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", config.TimeLogFilename, err)
+	}
+	status := timelog.NewDayStatus(entries, now, time.Duration(targetDailyHours*float64(time.Hour)))
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, status); err != nil {
+		return fmt.Errorf("failed to render the status line: %w", err)
+	}
+	if !asJSON {
+		_, err := fmt.Fprintln(c.stdout, text.String())
+		return err
+	}
+
+	output := statusBarOutput{
+		Text:       text.String(),
+		Tooltip:    fmt.Sprintf("Work %s, slack %s, %s left", timelog.FormatStatDuration(status.Work), timelog.FormatStatDuration(status.Slack), timelog.FormatStatDuration(status.Remaining)),
+		Class:      "working",
+		Percentage: min(status.Percent, 100),
+	}
+	switch {
+	case status.Arrived.IsZero():
+		output.Class = "away"
+	case status.Remaining == 0:
+		output.Class = "done"
+	}
+	return json.NewEncoder(c.stdout).Encode(output)
+}
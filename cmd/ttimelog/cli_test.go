@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCLI returns a cli on a timelog file with content in a temporary
+// directory, without a running TUI to talk to
+func newTestCLI(t *testing.T, content string) *cli {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, config.TimeLogFilename)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write timelog: %v", err)
+	}
+	return &cli{appConfig: &config.AppConfig{TimeLogDirPath: dir}, timeLogFilePath: path}
+}
+
+// runCLI runs the command and returns its exit code and output
+func runCLI(c *cli, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	c.stdout, c.stderr = &stdout, &stderr
+	code := c.run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func readTimelog(t *testing.T, c *cli) string {
+	t.Helper()
+	content, err := os.ReadFile(c.timeLogFilePath)
+	if err != nil {
+		t.Fatalf("Failed to read timelog: %v", err)
+	}
+	return string(content)
+}
+
+func lastEntry(t *testing.T, c *cli) timelog.Entry {
+	t.Helper()
+	entries, _, _, err := timelog.LoadEntries(c.timeLogFilePath)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+	return entries[len(entries)-1]
+}
+
+func TestRunExitCodes(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	content := timelog.FormatEntry(timelog.NewEntry(yesterday, "arrived**", 0), false)
+	c := newTestCLI(t, content)
+
+	code, stdout, stderr := runCLI(c, "help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Commands:")
+	assert.Empty(t, stderr)
+
+	code, stdout, stderr = runCLI(c, "nope")
+	assert.Equal(t, exitUsage, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, `unknown command "nope"`)
+
+	code, stdout, stderr = runCLI(c, "add")
+	assert.Equal(t, exitUsage, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "ttimelog add: nothing to log\nUsage: ttimelog add \"text\"\n", stderr)
+
+	code, stdout, stderr = runCLI(c, "export", "--from", "2025-03-10", "--to", "2025-03-01")
+	assert.Equal(t, exitUsage, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "--from is after --to")
+
+	code, stdout, stderr = runCLI(c, "report", "--template", "nope")
+	assert.Equal(t, exitFailure, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, `ttimelog report: no template "nope"`)
+
+	// nothing of the failed commands reached the file
+	assert.Equal(t, content, readTimelog(t, c))
+}
+
+func TestRunAddStartsDay(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	content := timelog.FormatEntry(timelog.NewEntry(yesterday.Add(-time.Hour), "arrived**", 0), false) +
+		timelog.FormatEntry(timelog.NewEntry(yesterday, "Acme: Web: fix login", time.Hour), false)
+	c := newTestCLI(t, content)
+
+	code, stdout, stderr := runCLI(c, "add", "arrived**")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	arrived := lastEntry(t, c)
+	// the day starts without a duration, after a blank line
+	assert.Equal(t, arrived.EndTime.Format("15:04")+" arrived**\n", stdout)
+	assert.Equal(t, content+"\n"+timelog.FormatEntryLine(arrived)+"\n", readTimelog(t, c))
+
+	// only the first arrival starts the day
+	code, _, _ = runCLI(c, "add", "arrived**")
+	assert.Equal(t, exitOK, code)
+	again := lastEntry(t, c)
+	assert.Equal(t, content+"\n"+timelog.FormatEntryLine(arrived)+"\n"+timelog.FormatEntryLine(again)+"\n", readTimelog(t, c))
+}
+
+func TestRunAddSlack(t *testing.T) {
+	now := time.Now()
+	arrived := now.Add(-30 * time.Minute)
+	if timelog.DateKey(arrived) != timelog.DateKey(now) {
+		t.Skip("too close to midnight")
+	}
+	c := newTestCLI(t, timelog.FormatEntry(timelog.NewEntry(arrived, "arrived**", 0), true))
+
+	code, stdout, stderr := runCLI(c, "add", "coffee", "**")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	coffee := lastEntry(t, c)
+	assert.Equal(t, "coffee **", coffee.Description)
+	assert.Equal(t, coffee.EndTime.Format("15:04")+" coffee ** (0h30m)\n", stdout)
+
+	// the break counts as slack, not as work
+	code, stdout, _ = runCLI(c, "status")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `Today\s+0h0m work\s+0h30m slack`, stdout)
+}
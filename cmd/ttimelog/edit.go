@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// entryForChange returns the selected entry and its line in the timelog
+// file, reporting why it can't be changed otherwise
+func (m *model) entryForChange() (timelog.Entry, string, bool) {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		m.status.Toast(statusWarn, "No entry selected")
+		return entry, "", false
+	}
+	if entry.Unsaved {
+		m.status.Toast(statusWarn, "Entry is not saved yet")
+		return entry, "", false
+	}
+
+	line, err := timelog.EntryLine(m.timeLogFilePath, entry)
+	if err != nil {
+		slog.Error("Failed to find entry in timelog file", "description", entry.Description, "error", err)
+		m.status.Toast(statusError, "Can't change entry: %v", err)
+		return entry, "", false
+	}
+	return entry, line, true
+}
+
+// startAmending puts the description of the selected entry in the input,
+// submitting it replaces the description while keeping the time
+func (m *model) startAmending() {
+	entry, _, ok := m.entryForChange()
+	if !ok {
+		return
+	}
+	m.amending = &entry
+	m.textInput.SetValue(entry.Description)
+	m.textInput.CursorEnd()
+	m.setFocus(focusFooter)
+}
+
+func (m *model) cancelAmending() {
+	m.amending = nil
+	m.textInput.Reset()
+}
+
+func (m *model) amendEntry(description string) {
+	entry := *m.amending
+	m.cancelAmending()
+
+	line, err := timelog.EntryLine(m.timeLogFilePath, entry)
+	if err == nil {
+		amended := entry
+		amended.Description = description
+		err = m.store.Apply(timelog.Operation{
+			Kind: timelog.OpEdit,
+			Line: entry.Line,
+			Old:  line,
+			Text: timelog.FormatEntryLine(amended),
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to amend entry", "description", entry.Description, "error", err)
+		m.status.Toast(statusError, "Can't change entry: %v", err)
+		return
+	}
+	m.refresh()
+}
+
+func (m *model) deleteSelectedEntry() {
+	entry, line, ok := m.entryForChange()
+	if !ok {
+		return
+	}
+	err := m.store.Apply(timelog.Operation{Kind: timelog.OpDelete, Line: entry.Line, Old: line})
+	if err != nil {
+		slog.Error("Failed to delete entry", "description", entry.Description, "error", err)
+		m.status.Toast(statusError, "Can't delete entry: %v", err)
+		return
+	}
+	m.status.Toast(statusInfo, "Deleted %q", entry.Description)
+	m.refresh()
+}
+
+func (m *model) undo() {
+	op, err := m.store.Undo()
+	m.reportJournal("Undid", op, err)
+}
+
+func (m *model) redo() {
+	op, err := m.store.Redo()
+	m.reportJournal("Redid", op, err)
+}
+
+func (m *model) reportJournal(verb string, op timelog.Operation, err error) {
+	switch {
+	case errors.Is(err, timelog.ErrNothingToUndo), errors.Is(err, timelog.ErrNothingToRedo):
+		m.status.Toast(statusInfo, "%v", err)
+		return
+	case errors.Is(err, timelog.ErrExternalChange):
+		m.status.Toast(statusWarn, "Not touching the file, it was changed outside ttimelog since the last change")
+		return
+	case err != nil:
+		slog.Error("Failed to undo/redo", "operation", op.Kind.String(), "error", err)
+		m.status.Toast(statusError, "%s %s failed: %v", verb, op.Kind, err)
+		return
+	}
+
+	text := op.Text
+	if op.Kind == timelog.OpDelete {
+		text = op.Old
+	}
+	m.status.Toast(statusInfo, "%s %s: %s", verb, op.Kind, text)
+	m.refresh()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/githook"
+	"github.com/Rash419/ttimelog/internal/keymap"
+	"github.com/Rash419/ttimelog/internal/watcher"
+)
+
+// gitTimeout bounds the git commands of the hook, committing waits for it
+const gitTimeout = 10 * time.Second
+
+func (c *cli) gitHook(args []string) error {
+	if len(args) == 0 {
+		return usageError{"install or post-commit?"}
+	}
+	switch args[0] {
+	case "install":
+		return c.installGitHook(args[1:])
+	case "post-commit":
+		return c.postCommit(args[1:])
+	}
+	return usageError{fmt.Sprintf("unknown git-hook command %q", args[0])}
+}
+
+func (c *cli) installGitHook(args []string) error {
+	flags := c.flagSet("git-hook install")
+	force := flags.Bool("force", false, "replace an existing post-commit hook")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	command := githook.HookCommand(executable)
+	path, err := githook.Install(ctx, dir, command, *force)
+	if errors.Is(err, githook.ErrHookExists) {
+		return fmt.Errorf("%s exists, add %q to it or use --force", path, command)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Installed %s, commits are %s\n", path, map[string]string{
+		config.GitSuggest: "suggested in ttimelog",
+		config.GitLog:     "logged right away",
+	}[c.appConfig.Git.Mode])
+	return nil
+}
+
+// postCommit is run by the hook in the repository after a commit
+func (c *cli) postCommit(args []string) error {
+	flags := c.flagSet("git-hook post-commit")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	commit, err := githook.ReadCommit(ctx, ".")
+	if err != nil {
+		return err
+	}
+	if commit.Replayed {
+		// the work was logged when the commit was first made
+		slog.Info("Skipping replayed commit", "repo", commit.Repo, "subject", commit.Subject)
+		return nil
+	}
+	description := commit.Description(c.appConfig.GitProjects)
+	slog.Info("Commit", "repo", commit.Repo, "branch", commit.Branch, "mode", c.appConfig.Git.Mode)
+
+	if c.appConfig.Git.Mode == config.GitLog {
+		return c.logEntry(description)
+	}
+	path := filepath.Join(c.appConfig.TimeLogDirPath, config.SuggestionsFile)
+	if err := githook.AddSuggestion(path, description); err != nil {
+		return fmt.Errorf("failed to add suggestion to [%s] with error[%v]", path, err)
+	}
+	fmt.Fprintf(c.stdout, "ttimelog: suggested %q\n", description)
+	return nil
+}
+
+// suggestionsWatcher reloads the commits suggested by the hook
+func suggestionsWatcher(ctx context.Context, wg *sync.WaitGroup, st *store, path string) error {
+	defer wg.Done()
+
+	return watcher.Watch(ctx, path, watcher.Options{
+		OnChange: st.LoadSuggestions,
+		OnError: func(err error) {
+			slog.Error("Watching suggestions failed", "error", err)
+		},
+	})
+}
+
+// useSuggestion puts the oldest suggestion into the empty input, to be
+// edited or logged
+func (m *model) useSuggestion() {
+	suggestion := m.suggestions[0]
+	m.dropSuggestion()
+	m.textInput.SetValue(suggestion)
+	m.textInput.CursorEnd()
+}
+
+func (m *model) dropSuggestion() {
+	err := m.store.DropSuggestion(m.suggestions[0])
+	m.refresh()
+	if err != nil {
+		slog.Error("Failed to remove suggestion", "error", err)
+		m.status.Toast(statusError, "Failed to remove suggestion: %v", err)
+	}
+}
+
+// offersSuggestion is whether a suggestion is shown in the input, which
+// has to be empty and focused
+func (m model) offersSuggestion() bool {
+	return len(m.suggestions) > 0 && m.focus == focusFooter && m.amending == nil && m.textInput.Value() == ""
+}
+
+// suggestionPlaceholder offers the oldest suggestion in the empty input
+func (m model) suggestionPlaceholder() string {
+	more := ""
+	if len(m.suggestions) > 1 {
+		more = fmt.Sprintf(" (+%d)", len(m.suggestions)-1)
+	}
+	return fmt.Sprintf("%s: %s%s · %s: dismiss", m.keys.Binding(keymap.UseSuggestion).Help().Key, m.suggestions[0], more,
+		m.keys.Binding(keymap.DropSuggestion).Help().Key)
+}
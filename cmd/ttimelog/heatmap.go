@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// heatmapWeeks is the number of week columns in the calendar, 12 months
+// plus the current week.
+const heatmapWeeks = 53
+
+// monochromeLevels replace the level colours when colours are disabled
+var monochromeLevels = []string{"░", "▒", "▓", "█"}
+
+var weekdayLabels = []string{"Mon", "   ", "Wed", "   ", "Fri", "   ", "Sun"}
+
+// heatmap is a GitHub-style calendar of worked hours, one column per week
+// and one row per weekday, shaded against the daily target.
+type heatmap struct {
+	days        map[string]timelog.Stats
+	start       time.Time // Monday of the first column
+	today       time.Time
+	cursor      time.Time
+	width       int
+	targetHours float64
+	isHoliday   func(time.Time) bool
+}
+
+// weekdayIndex returns the row of the day with Monday as 0
+func weekdayIndex(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+func newHeatmap(entries []timelog.Entry, now time.Time, targetHours float64, isHoliday func(time.Time) bool) heatmap {
+	today := timelog.StartOfDay(now)
+	monday := today.AddDate(0, 0, -weekdayIndex(today))
+	return heatmap{
+		days:        timelog.SummarizeDays(entries),
+		start:       monday.AddDate(0, 0, -7*(heatmapWeeks-1)),
+		today:       today,
+		cursor:      today,
+		targetHours: targetHours,
+		isHoliday:   isHoliday,
+	}
+}
+
+func (h *heatmap) SetWidth(width int) {
+	h.width = width
+}
+
+// Move shifts the cursor by the given number of days, staying inside the calendar
+func (h *heatmap) Move(days int) {
+	cursor := h.cursor.AddDate(0, 0, days)
+	if cursor.Before(h.start) || cursor.After(h.today) {
+		return
+	}
+	h.cursor = cursor
+}
+
+func (h *heatmap) MoveToToday() {
+	h.cursor = h.today
+}
+
+func (h heatmap) Selected() time.Time {
+	return h.cursor
+}
+
+func (h heatmap) column(t time.Time) int {
+	// round as days around DST changes are not exactly 24h
+	return int(math.Round(timelog.StartOfDay(t).Sub(h.start).Hours()/24)) / 7
+}
+
+func (h heatmap) holiday(t time.Time) bool {
+	return h.isHoliday != nil && h.isHoliday(t)
+}
+
+func (h heatmap) cell(day time.Time) string {
+	if day.After(h.today) {
+		return " "
+	}
+
+	work := h.days[timelog.DateKey(day)].Work.Hours()
+	weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+
+	t := theme.Active()
+	glyph := "■"
+	color := t.Muted
+	switch {
+	case work <= 0 && h.holiday(day):
+		glyph, color = "◆", t.Warning
+	case work <= 0 && weekend:
+		glyph = "□"
+	case work <= 0:
+		if t.Monochrome {
+			glyph = "·"
+		}
+	case work > h.targetHours*1.25:
+		color = t.Danger
+		if t.Monochrome {
+			glyph = "▲"
+		}
+	default:
+		level := min(int(work/h.targetHours*float64(len(t.Levels))), len(t.Levels)-1)
+		color = t.Levels[level]
+		if t.Monochrome {
+			glyph = monochromeLevels[min(level, len(monochromeLevels)-1)]
+		}
+	}
+	if work > 0 && h.holiday(day) {
+		glyph = "◆"
+	}
+
+	style := lipgloss.NewStyle().Foreground(color)
+	if day.Equal(h.cursor) {
+		style = style.Reverse(true)
+	}
+	return style.Render(glyph)
+}
+
+func (h heatmap) visibleColumns() (int, int) {
+	labelWidth := len(weekdayLabels[0]) + 1
+	cols := min(max((h.width-labelWidth)/2, 1), heatmapWeeks)
+
+	first := heatmapWeeks - cols
+	if cursorCol := h.column(h.cursor); cursorCol < first {
+		first = cursorCol
+	}
+	return first, cols
+}
+
+func (h heatmap) monthRow(first, cols int) string {
+	row := []rune(strings.Repeat(" ", cols*2))
+	previousMonth := time.Month(0)
+	for col := range cols {
+		monday := h.start.AddDate(0, 0, 7*(first+col))
+		month := monday.Month()
+		if month != previousMonth && col*2+3 <= len(row) {
+			copy(row[col*2:], []rune(monday.Format("Jan")))
+		}
+		previousMonth = month
+	}
+	return strings.Repeat(" ", len(weekdayLabels[0])+1) + string(row)
+}
+
+func (h heatmap) legend() string {
+	t := theme.Active()
+	if t.Monochrome {
+		return "Less ·" + strings.Join(monochromeLevels, "") + " More  ▲ Overwork  ◆ Holiday  □ Weekend"
+	}
+
+	var b strings.Builder
+	b.WriteString("Less ")
+	b.WriteString(lipgloss.NewStyle().Foreground(t.Muted).Render("■"))
+	for _, color := range t.Levels {
+		b.WriteString(lipgloss.NewStyle().Foreground(color).Render("■"))
+	}
+	b.WriteString(" More  ")
+	b.WriteString(lipgloss.NewStyle().Foreground(t.Danger).Render("■"))
+	b.WriteString(" Overwork  ")
+	b.WriteString(lipgloss.NewStyle().Foreground(t.Warning).Render("◆"))
+	b.WriteString(" Holiday  □ Weekend")
+	return b.String()
+}
+
+func (h heatmap) selectedSummary() string {
+	stats := h.days[timelog.DateKey(h.cursor)]
+	summary := fmt.Sprintf("%s  Work: %s / %s, Slack: %s",
+		h.cursor.Format("Mon 02 Jan 2006"),
+		timelog.FormatStatDuration(stats.Work),
+		timelog.FormatStatDuration(time.Duration(h.targetHours*float64(time.Hour))),
+		timelog.FormatStatDuration(stats.Slack))
+	if h.holiday(h.cursor) {
+		summary += " (holiday)"
+	}
+	return summary
+}
+
+func (h heatmap) View() string {
+	first, cols := h.visibleColumns()
+
+	lines := []string{h.monthRow(first, cols)}
+	for weekday, label := range weekdayLabels {
+		var b strings.Builder
+		b.WriteString(label)
+		b.WriteString(" ")
+		for col := range cols {
+			day := h.start.AddDate(0, 0, 7*(first+col)+weekday)
+			b.WriteString(h.cell(day))
+			b.WriteString(" ")
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	lines = append(lines, "", h.legend(), h.selectedSummary())
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Rash419/ttimelog/internal/keymap"
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpView lists the active keybindings of the context it was opened from
+type helpView struct {
+	entries   []keymap.HelpEntry
+	keysWidth int
+	descWidth int
+}
+
+func newHelpView(keys keymap.KeyMap, ctx keymap.Context) helpView {
+	h := helpView{entries: keys.Help(ctx)}
+	for _, entry := range h.entries {
+		h.keysWidth = max(h.keysWidth, lipgloss.Width(entry.Keys))
+		h.descWidth = max(h.descWidth, lipgloss.Width(entry.Desc))
+	}
+	return h
+}
+
+// Width is the width of the pane needed to show the help without wrapping
+func (h helpView) Width() int {
+	return h.keysWidth + h.descWidth + 2
+}
+
+func (h helpView) View() string {
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Active().Accent).Width(h.keysWidth + 2)
+	lines := make([]string, 0, len(h.entries))
+	for _, entry := range h.entries {
+		lines = append(lines, keyStyle.Render(entry.Keys)+entry.Desc)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,53 +2,91 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"math"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Rash419/ttimelog/internal/api"
 	"github.com/Rash419/ttimelog/internal/chrono"
 	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/dashboard"
+	"github.com/Rash419/ttimelog/internal/editor"
+	"github.com/Rash419/ttimelog/internal/keymap"
 	"github.com/Rash419/ttimelog/internal/layout"
+	"github.com/Rash419/ttimelog/internal/theme"
 	"github.com/Rash419/ttimelog/internal/timelog"
 	"github.com/Rash419/ttimelog/internal/treeview"
+	"github.com/Rash419/ttimelog/internal/watcher"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/fsnotify/fsnotify"
+	"github.com/muesli/termenv"
 	overlay "github.com/rmhubbert/bubbletea-overlay"
 )
 
 type model struct {
-	textInput             textinput.Model
-	taskTable             table.Model
-	err                   error
-	width                 int
-	height                int
-	entries               []timelog.Entry
-	statsCollection       timelog.StatsCollection
-	scrollToBottom        bool
-	handledArrivedMessage bool
-	ctx                   context.Context
-	cancel                context.CancelFunc
-	wg                    *sync.WaitGroup
-	timeLogFilePath       string
-	focus                 Focus
-	showProjectOverlay    bool
-	projectTree           *treeview.TreeView
+	textInput textinput.Model
+	taskTable table.Model
+	err       error
+	width     int
+	height    int
+	// store is the timelog state shared with the other sessions, entries
+	// and the fields up to projectsFetchedAt are its last snapshot
+	store           *store
+	entries         []timelog.Entry
+	statsCollection timelog.StatsCollection
+	suggestions     []string
+	projectIndex    chrono.ProjectIndex
+	// projectsFetchedAt is the age of the cached Chronophage project list
+	projectsFetchedAt time.Time
+	// projectRoot is the tree projectTree was built from
+	projectRoot     *treeview.TreeNode
+	projectTree     *treeview.TreeView
+	scrollToBottom  bool
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              *sync.WaitGroup
+	timeLogFilePath string
+	focus           Focus
+	overlay         overlayKind
+	heatmap         heatmap
+	charts          charts
+	timesheet       timesheetView
+	remap           remapView
+	standup         standupView
+	// remapFrom is the project being renamed while picking its new name in
+	// the project list
+	remapFrom  string
+	keys       keymap.KeyMap
+	helpView   helpView
+	helpReturn overlayKind
+	status     statusBar
+	// pendingCmds are commands queued by key handlers, returned by Update
+	pendingCmds []tea.Cmd
+	// clipboard is the terminal copied to with OSC 52
+	clipboard *termenv.Output
+	// amending is the entry whose description is being changed in the input
+	amending  *timelog.Entry
+	appConfig *config.AppConfig
+	// viewDate is the day shown in the table, zero means today
+	viewDate time.Time
 }
 
 const (
 	HeaderHeight = 3
 	StatsHeight  = 5
 	FooterHeight = 2
+	StatusHeight = 1
 )
 
 // TODO: update dynamically using config
@@ -61,80 +99,209 @@ type (
 	errMsg error
 )
 
-func initialModel(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, appConfig *config.AppConfig) model {
+func initialModel(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup, st *store, keys keymap.KeyMap) model {
 	txtInput := textinput.New()
 	txtInput.Placeholder = "What are you working on?"
 	txtInput.Focus()
 
-	timeLogFilePath := filepath.Join(appConfig.TimeLogDirPath, config.TimeLogFilename)
-	entries, statsCollections, handledArrivedMessage, err := timelog.LoadEntries(timeLogFilePath)
-	if err != nil {
-		slog.Error("Failed to load entries", "error", err)
-	}
+	var status statusBar
+	taskTable := createBodyContent(0, 0, nil, time.Now())
+	taskTable.KeyMap.LineUp = keys.Binding(keymap.Up)
+	taskTable.KeyMap.LineDown = keys.Binding(keymap.Down)
 
-	taskTable := createBodyContent(0, 0, entries)
-
-	projectListFile := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile)
-	rootNode, err := chrono.ParseProjectList(projectListFile)
-	if err != nil {
-		slog.Error("Failed to parse project list", "error", err.Error())
+	if st.queueErr != nil {
+		status.Toast(statusWarn, "Queued timesheets unavailable: %v", st.queueErr)
 	}
-	projectTree := treeview.NewTreeView(rootNode)
 
-	return model{
-		textInput:             txtInput,
-		err:                   nil,
-		entries:               entries,
-		taskTable:             taskTable,
-		statsCollection:       statsCollections,
-		scrollToBottom:        true,
-		handledArrivedMessage: handledArrivedMessage,
-		ctx:                   ctx,
-		cancel:                cancel,
-		wg:                    wg,
-		timeLogFilePath:       timeLogFilePath,
-		focus:                 focusFooter,
-		projectTree:           projectTree,
+	m := model{
+		textInput:       txtInput,
+		err:             nil,
+		store:           st,
+		taskTable:       taskTable,
+		scrollToBottom:  true,
+		ctx:             ctx,
+		cancel:          cancel,
+		wg:              wg,
+		timeLogFilePath: st.timeLogFilePath,
+		focus:           focusFooter,
+		appConfig:       st.appConfig,
+		keys:            keys,
+		status:          status,
+		clipboard:       termenv.NewOutput(os.Stdout),
 	}
+	m.refresh()
+	return m
 }
 
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		tea.SetWindowTitle("Time log"),
 		textinput.Blink,
+		m.status.ExpireCmd(),
+		loadProjectsCmd(m.store, false),
+		flushQueueCmd(m.ctx, m.store.queue, m.appConfig),
+		queueTickCmd(),
 	)
 }
 
+type projectsLoadedMsg struct {
+	err   error
+	force bool
+}
+
+// loadProjectsCmd loads the project sources in the background so a slow
+// server doesn't delay startup. Only the first program loads them unless
+// forced, the others get them from the store.
+func loadProjectsCmd(st *store, force bool) tea.Cmd {
+	return func() tea.Msg {
+		loaded, err := st.LoadProjects(force)
+		if !loaded {
+			return nil
+		}
+		return projectsLoadedMsg{err: err, force: force}
+	}
+}
+
+func (m *model) handleProjectsLoaded(msg projectsLoadedMsg) {
+	m.refresh()
+	if msg.err != nil {
+		slog.Error("Failed to load projects", "error", msg.err)
+		cached := ""
+		if !m.projectsFetchedAt.IsZero() {
+			cached = fmt.Sprintf(" (using cached list from %s ago)", formatAge(time.Since(m.projectsFetchedAt)))
+		}
+		validation := "projects are not checked"
+		if !m.projectIndex.Empty() {
+			validation = "projects are checked against the loaded part only"
+		}
+		toast := m.status.Append
+		if msg.force {
+			// asked for, it replaces "Fetching project list…"
+			toast = m.status.Toast
+		}
+		toast(statusWarn, "Project list incomplete%s, %s: %v", cached, validation, strings.ReplaceAll(msg.err.Error(), "\n", "; "))
+	} else if msg.force {
+		m.status.Toast(statusInfo, "Project list updated")
+	}
+}
+
 func (m *model) handleInput() {
 	val := m.textInput.Value()
 	if val == "" {
 		return
 	}
+	if m.amending != nil {
+		m.amendEntry(val)
+		return
+	}
 
-	var lastTaskTime time.Time
-	handleArrivedMessage := timelog.IsArrivedMessage(val) && !m.handledArrivedMessage
-	if len(m.entries) == 0 || handleArrivedMessage {
-		lastTaskTime = time.Now()
-		m.handledArrivedMessage = true
-	} else {
-		lastTaskTime = m.entries[len(m.entries)-1].EndTime
+	m.logEntry(val)
+	m.textInput.Reset()
+}
+
+// logEntry appends an entry ending now, like typing description in the input
+func (m *model) logEntry(description string) timelog.Entry {
+	newEntry, err := m.store.Log(description, time.Now())
+	if err != nil {
+		m.setSaveError(err)
 	}
+	// always land back on today after logging
+	m.viewDate = time.Time{}
+	m.refresh()
+	return newEntry
+}
+
+func (m model) hasUnsavedEntries() bool {
+	for _, entry := range m.entries {
+		if entry.Unsaved {
+			return true
+		}
+	}
+	return false
+}
 
-	// update table
-	newEntry := timelog.NewEntry(time.Now(), val, time.Since(lastTaskTime))
+func (m *model) setSaveError(err error) {
+	m.status.SetError(errorSourceSave, "Entries not saved to %s: %v. Press %s to retry",
+		config.TimeLogFilename, err, m.keys.Binding(keymap.RetrySave).Help().Key)
+}
 
-	m.entries = append(m.entries, newEntry)
-	if err := timelog.SaveEntry(newEntry, handleArrivedMessage, m.timeLogFilePath); err != nil {
-		slog.Error("Failed to add entry with description", "error", newEntry.Description)
+// retrySaves writes the unsaved entries in order, stopping at the first failure
+func (m *model) retrySaves() {
+	saved, err := m.store.RetrySaves()
+	m.refresh()
+	if err != nil {
+		m.setSaveError(err)
+		return
 	}
 
-	rows := getTableRows(m.entries)
-	m.taskTable.SetRows(rows)
-	m.scrollToBottom = true
+	m.status.ClearError(errorSourceSave)
+	if saved > 0 {
+		m.status.Toast(statusInfo, "Saved %d entries to %s", saved, config.TimeLogFilename)
+	}
+}
 
-	timelog.UpdateStatsCollection(newEntry, &m.statsCollection)
+func (m *model) queueCmd(cmd tea.Cmd) {
+	m.pendingCmds = append(m.pendingCmds, cmd)
+}
 
-	m.textInput.Reset()
+// selectedEntry returns the entry under the table cursor
+func (m model) selectedEntry() (timelog.Entry, bool) {
+	day := timelog.DateKey(m.tableDate())
+	row := 0
+	for _, entry := range m.entries {
+		if timelog.DateKey(entry.EndTime) != day {
+			continue
+		}
+		if row == m.taskTable.Cursor() {
+			return entry, true
+		}
+		row++
+	}
+	return timelog.Entry{}, false
+}
+
+type editorFinishedMsg struct {
+	err error
+}
+
+// editSelectedEntry suspends the TUI and opens the timelog file in the
+// user's editor on the line of the selected entry
+func (m *model) editSelectedEntry() {
+	line := 0
+	if entry, ok := m.selectedEntry(); ok {
+		line = entry.Line
+	}
+
+	cmd, err := editor.Command(m.timeLogFilePath, line)
+	if err != nil {
+		m.status.Toast(statusError, "Can't open editor: %v", err)
+		return
+	}
+	m.queueCmd(tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	}))
+}
+
+func (m *model) handleEditorFinished(msg editorFinishedMsg) {
+	if msg.err != nil {
+		slog.Error("Editor exited with error", "error", msg.err)
+		m.status.Toast(statusError, "Editor failed: %v", msg.err)
+	}
+	if err := m.store.Reload(); err != nil {
+		slog.Error("Failed to load entries on reload", "error", err)
+	}
+	m.refresh()
+	if _, failed := m.status.errors[errorSourceLoad]; !failed && msg.err == nil {
+		m.status.Toast(statusInfo, "Reloaded %s", config.TimeLogFilename)
+	}
+}
+
+// toastCmd schedules the expiry of a toast shown since previousID
+func (m model) toastCmd(previousID int) tea.Cmd {
+	if m.status.toastID == previousID {
+		return nil
+	}
+	return m.status.ExpireCmd()
 }
 
 func (m *model) handleWindowSize(msg tea.WindowSizeMsg) {
@@ -149,12 +316,25 @@ func (m *model) handleWindowSize(msg tea.WindowSizeMsg) {
 	// Update table dimensions
 	newCols := getTableCols(int(math.Round(float64(availableWidth) / 1.3)))
 	m.taskTable.SetColumns(newCols)
-	fixedHeight := HeaderHeight + StatsHeight + FooterHeight + 2
+	fixedHeight := HeaderHeight + StatsHeight + FooterHeight + StatusHeight + 2
 	bodyHeight := max(msg.Height-fixedHeight, 1)
 	m.taskTable.SetHeight(bodyHeight)
 
 	// Update size of projectTree
 	m.projectTree.SetSize(int(math.Round(float64(m.width)*0.25)), int(math.Round(float64(m.height)*0.25)))
+	m.heatmap.SetWidth(heatmapPaneWidth(m.width))
+	m.charts.SetSize(chartsPaneSize(m.width, m.height))
+	m.timesheet.SetSize(chartsPaneSize(m.width, m.height))
+	m.remap.SetSize(chartsPaneSize(m.width, m.height))
+	m.standup.SetSize(chartsPaneSize(m.width, m.height))
+}
+
+// tableDate returns the day whose entries are listed in the table
+func (m model) tableDate() time.Time {
+	if m.viewDate.IsZero() {
+		return time.Now()
+	}
+	return m.viewDate
 }
 
 func (m *model) updateComponents(msg tea.Msg) []tea.Cmd {
@@ -179,17 +359,30 @@ func (m *model) updateComponents(msg tea.Msg) []tea.Cmd {
 
 type shutdownCompleteMsg struct{}
 
-func (m *model) handleFileChangedMsg() {
-	entries, statsCollections, handledArrivedMessage, err := timelog.LoadEntries(m.timeLogFilePath)
-	if err != nil {
-		slog.Error("Failed to load entries on reload", "error", err)
-		return
+// refresh takes a new snapshot of the store after a change made by this or
+// another program
+func (m *model) refresh() {
+	state := m.store.Snapshot()
+	if state.loadErr != nil {
+		m.status.SetError(errorSourceLoad, "Failed to load %s: %v", config.TimeLogFilename, state.loadErr)
+	} else {
+		m.status.ClearError(errorSourceLoad)
+		m.status.ClearError(errorSourceWatch)
+	}
+	m.entries = state.entries
+	m.statsCollection = state.statsCollection
+	m.suggestions = state.suggestions
+	m.projectIndex = state.projectIndex
+	m.projectsFetchedAt = state.projectsFetchedAt
+	if state.projectRoot != m.projectRoot {
+		// a copy, the expanded nodes are this program's
+		m.projectRoot = state.projectRoot
+		m.projectTree = treeview.NewTreeView(state.projectRoot.Clone())
+		m.projectTree.Styles = treeStyles()
+		m.projectTree.SetSize(int(math.Round(float64(m.width)*0.25)), int(math.Round(float64(m.height)*0.25)))
 	}
-	m.entries = entries
-	m.statsCollection = statsCollections
-	m.handledArrivedMessage = handledArrivedMessage
 
-	rows := getTableRows(m.entries)
+	rows := getTableRows(m.entries, m.tableDate())
 	m.taskTable.SetRows(rows)
 	m.scrollToBottom = true
 }
@@ -210,88 +403,478 @@ const (
 	focusTable
 	focusFooter
 	focusProjectTree
+	focusHeatmap
+	focusCharts
+	focusTimesheet
+	focusRemap
+	focusStandup
+)
+
+type overlayKind int
+
+const (
+	overlayNone overlayKind = iota
+	overlayProjects
+	overlayHeatmap
+	overlayCharts
+	overlayTimesheet
+	overlayRemap
+	overlayStandup
+	overlayHelp
 )
 
+func (m *model) setFocus(focus Focus) {
+	m.focus = focus
+	m.textInput.Blur()
+	m.taskTable.Blur()
+	switch focus {
+	case focusTable:
+		m.taskTable.Focus()
+	case focusFooter:
+		m.textInput.Focus()
+	}
+}
+
+// keyContext returns which set of bindings is active
+func (m model) keyContext() keymap.Context {
+	switch m.overlay {
+	case overlayProjects:
+		return keymap.ContextProjects
+	case overlayHeatmap:
+		return keymap.ContextHeatmap
+	case overlayCharts:
+		return keymap.ContextCharts
+	case overlayTimesheet:
+		return keymap.ContextTimesheet
+	case overlayRemap:
+		return keymap.ContextRemap
+	case overlayStandup:
+		return keymap.ContextStandup
+	case overlayHelp:
+		return keymap.ContextHelp
+	}
+	return keymap.ContextMain
+}
+
+func (m *model) closeOverlay() {
+	m.overlay = overlayNone
+	m.remapFrom = ""
+	m.setFocus(focusFooter)
+}
+
+func (m *model) openHelp() {
+	m.helpView = newHelpView(m.keys, m.keyContext())
+	m.helpReturn = m.overlay
+	m.overlay = overlayHelp
+}
+
+func (m *model) handleHelpKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Close), m.keys.Matches(msg, keymap.Help):
+		m.overlay = m.helpReturn
+	}
+	return keyHandled
+}
+
 func (m *model) handleProjectTreeKeyMsg(msg tea.KeyMsg) keyResult {
-	switch msg.String() {
-	case "ctrl+c":
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
 		return keyExit
-	case "j", "down":
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.Down):
 		m.projectTree.MoveDown()
-		return keyHandled
-	case "k", "up":
+	case m.keys.Matches(msg, keymap.Up):
 		m.projectTree.MoveUp()
-		return keyHandled
-	case " ": // space
+	case m.keys.Matches(msg, keymap.Toggle):
 		m.projectTree.Toggle()
-		return keyHandled
-	case "enter":
+	case m.keys.Matches(msg, keymap.Refresh):
+		m.status.Toast(statusInfo, "Fetching project list…")
+		m.queueCmd(loadProjectsCmd(m.store, true))
+	case m.keys.Matches(msg, keymap.Select) && m.remapFrom != "":
+		// any level can be the new name of a project
+		if node := m.projectTree.SelectedNode(); node != nil && node.Path != "" {
+			m.openRemap(timelog.Remapping{{From: m.remapFrom, To: node.Path}})
+			m.remapFrom = ""
+		}
+	case m.keys.Matches(msg, keymap.Select):
 		projectPath := m.projectTree.GetProjectPath()
 		if projectPath != "" {
 			m.textInput.SetValue(projectPath)
-			m.showProjectOverlay = false
-			m.focus = focusFooter
+			m.closeOverlay()
 		}
-	case "esc":
-		m.showProjectOverlay = false
-		return keyHandled
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
 	}
 	return keyHandled
 }
 
+func (m *model) handleHeatmapKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.Left):
+		m.heatmap.Move(-7)
+	case m.keys.Matches(msg, keymap.Right):
+		m.heatmap.Move(7)
+	case m.keys.Matches(msg, keymap.Up):
+		m.heatmap.Move(-1)
+	case m.keys.Matches(msg, keymap.Down):
+		m.heatmap.Move(1)
+	case m.keys.Matches(msg, keymap.Today):
+		m.heatmap.MoveToToday()
+	case m.keys.Matches(msg, keymap.Select):
+		m.viewDate = time.Time{}
+		if selected := m.heatmap.Selected(); timelog.DateKey(selected) != timelog.DateKey(time.Now()) {
+			m.viewDate = selected
+		}
+		m.taskTable.SetRows(getTableRows(m.entries, m.tableDate()))
+		m.scrollToBottom = true
+		m.overlay = overlayNone
+		m.setFocus(focusTable)
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
+	}
+	return keyHandled
+}
+
+func (m *model) handleChartsKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.SwitchChart):
+		m.charts.ToggleKind()
+	case m.keys.Matches(msg, keymap.Week):
+		m.charts.SetPeriod(periodWeek)
+	case m.keys.Matches(msg, keymap.Month):
+		m.charts.SetPeriod(periodMonth)
+	case m.keys.Matches(msg, keymap.Left):
+		m.charts.Shift(-1)
+	case m.keys.Matches(msg, keymap.Right):
+		m.charts.Shift(1)
+	case m.keys.Matches(msg, keymap.Category):
+		m.charts.ToggleCategory()
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
+	}
+	return keyHandled
+}
+
+func (m *model) openCharts() {
+	m.charts = newCharts(m.entries, time.Now(), targetDailyHours, m.appConfig.IsHoliday)
+	m.charts.SetSize(chartsPaneSize(m.width, m.height))
+	m.charts.help = m.keys.ShortHelp(keymap.ContextCharts,
+		keymap.SwitchChart, keymap.Week, keymap.Month, keymap.Left, keymap.Right, keymap.Category)
+	m.overlay = overlayCharts
+	m.focus = focusCharts
+}
+
+func (m *model) handleTimesheetKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.Day):
+		m.timesheet.SetPeriod(periodDay)
+	case m.keys.Matches(msg, keymap.Week):
+		m.timesheet.SetPeriod(periodWeek)
+	case m.keys.Matches(msg, keymap.Left):
+		m.timesheet.Shift(-1)
+	case m.keys.Matches(msg, keymap.Right):
+		m.timesheet.Shift(1)
+	case m.keys.Matches(msg, keymap.Up):
+		m.timesheet.Scroll(-1)
+	case m.keys.Matches(msg, keymap.Down):
+		m.timesheet.Scroll(1)
+	case m.keys.Matches(msg, keymap.Select):
+		m.submitTimesheet()
+	case m.keys.Matches(msg, keymap.DeleteEntry):
+		m.dropRejected()
+	case m.keys.Matches(msg, keymap.CheckProjects):
+		m.timesheet.ToggleChecking()
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
+	}
+	return keyHandled
+}
+
+func (m *model) openTimesheet() {
+	submitted, err := chrono.LoadSubmitted(m.appConfig.TimeLogDirPath)
+	if err != nil {
+		slog.Error("Failed to load submitted days", "error", err)
+		m.status.Toast(statusWarn, "Can't tell which days were submitted: %v", err)
+	}
+	m.timesheet = newTimesheetView(m.entries, time.Now(), submitted, m.appConfig.Gtimelog.DryRun)
+	m.timesheet.queue = m.store.queue
+	m.timesheet.queued = m.store.queue.Dates()
+	m.timesheet.projects = m.projectIndex
+	m.timesheet.SetSize(chartsPaneSize(m.width, m.height))
+	m.timesheet.help = m.keys.ShortHelp(keymap.ContextTimesheet,
+		keymap.Day, keymap.Week, keymap.Left, keymap.Right, keymap.Select, keymap.CheckProjects)
+	m.overlay = overlayTimesheet
+	m.focus = focusTimesheet
+}
+
+func (m *model) submitTimesheet() {
+	if m.timesheet.submitting {
+		return
+	}
+	sheet := m.timesheet.Pending()
+	if len(sheet.Days) == 0 {
+		m.status.Toast(statusInfo, "Nothing left to submit for %s", m.timesheet.period.Title())
+		return
+	}
+
+	if m.appConfig.Gtimelog.DryRun {
+		m.timesheet.submitting = true
+		m.queueCmd(submitTimesheetCmd(m.ctx, m.appConfig, sheet))
+		return
+	}
+
+	// everything goes through the queue, so nothing is lost while offline
+	if err := m.store.queue.Enqueue(sheet); err != nil {
+		slog.Error("Failed to queue timesheet", "error", err)
+		m.status.Toast(statusError, "Can't queue timesheet: %v", err)
+		return
+	}
+	for _, date := range sheet.Dates() {
+		m.timesheet.queued[date] = true
+	}
+	m.timesheet.submitting = true
+	m.queueCmd(flushQueueCmd(m.ctx, m.store.queue, m.appConfig))
+}
+
+// handleTimesheetSubmitted reports dry runs, which bypass the queue
+func (m *model) handleTimesheetSubmitted(msg timesheetSubmittedMsg) {
+	m.timesheet.submitting = false
+	if msg.err != nil {
+		slog.Error("Failed to submit timesheet", "error", msg.err)
+		m.status.Toast(statusError, "Submitting timesheet failed: %v", msg.err)
+		return
+	}
+	m.status.Toast(statusInfo, "Dry run: would submit %s over %d day(s), see %s",
+		timelog.FormatStatDuration(msg.sheet.Total()), len(msg.sheet.Days), config.TimeLogFile)
+}
+
+func (m *model) handleQueueFlushed(msg queueFlushedMsg) {
+	m.timesheet.submitting = false
+	m.timesheet.queued = m.store.queue.Dates()
+
+	var total time.Duration
+	for _, item := range msg.sent {
+		total += item.Sheet.Total()
+		for _, date := range item.Sheet.Dates() {
+			m.timesheet.submitted[date] = true
+		}
+	}
+
+	var rejected *chrono.RejectedError
+	var unrecorded *chrono.UnrecordedError
+	switch {
+	case errors.As(msg.err, &rejected):
+		m.status.Toast(statusError, "Chronophage rejected a timesheet: %v", rejected)
+	case errors.As(msg.err, &unrecorded):
+		m.status.Toast(statusWarn, "Submitted %s, but %s wasn't updated: %v",
+			timelog.FormatStatDuration(total), config.SubmittedFile, unrecorded.Err)
+	case msg.err != nil:
+		slog.Warn("Timesheet submission postponed", "error", msg.err)
+		m.status.Toast(statusWarn, "Can't reach Chronophage, %d timesheet(s) queued for retry: %v", len(m.store.queue.Items()), msg.err)
+	case len(msg.sent) > 0:
+		m.status.Toast(statusInfo, "Submitted %s from %d queued timesheet(s)", timelog.FormatStatDuration(total), len(msg.sent))
+	}
+}
+
+func (m *model) dropRejected() {
+	dropped := 0
+	for _, item := range m.store.queue.Items() {
+		if !item.Rejected {
+			continue
+		}
+		if err := m.store.queue.Remove(item.ID); err != nil {
+			m.status.Toast(statusError, "Can't update the queue: %v", err)
+			return
+		}
+		dropped++
+	}
+	m.timesheet.queued = m.store.queue.Dates()
+	if dropped > 0 {
+		m.status.Toast(statusInfo, "Dropped %d rejected timesheet(s)", dropped)
+	}
+}
+
+func (m model) projectsTitle() string {
+	if m.remapFrom != "" {
+		return "Rename " + m.remapFrom + " to"
+	}
+	if m.projectsFetchedAt.IsZero() {
+		return "Projects"
+	}
+	return fmt.Sprintf("Projects (updated %s ago)", formatAge(time.Since(m.projectsFetchedAt)))
+}
+
+// formatAge renders a duration in its largest unit, e.g. "3h"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func (m *model) openHeatmap() {
+	m.heatmap = newHeatmap(m.entries, time.Now(), targetDailyHours, m.appConfig.IsHoliday)
+	m.heatmap.SetWidth(heatmapPaneWidth(m.width))
+	m.overlay = overlayHeatmap
+	m.focus = focusHeatmap
+}
+
 func (m *model) handleKeyMsg(msg tea.KeyMsg) keyResult {
-	switch msg.String() {
-	case "ctrl+c":
+	if m.keys.Matches(msg, keymap.Quit) {
 		return keyExit
-	case "enter":
+	}
+
+	// typing into the input takes precedence over single key bindings
+	typing := msg.Type == tea.KeyRunes && !msg.Alt || msg.Type == tea.KeySpace
+	if m.focus == focusFooter && typing {
+		return keyIgnored
+	}
+
+	switch {
+	case m.keys.Matches(msg, keymap.Submit):
 		m.handleInput()
-		return keyHandled
-	case "ctrl+p":
-		m.showProjectOverlay = true
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.ToggleFocus) && m.amending != nil:
+		m.cancelAmending()
+	case m.keys.Matches(msg, keymap.ToggleFocus):
+		if m.focus == focusFooter {
+			m.setFocus(focusTable)
+		} else {
+			m.setFocus(focusFooter)
+		}
+	case m.keys.Matches(msg, keymap.RetrySave):
+		m.retrySaves()
+	case m.keys.Matches(msg, keymap.EditEntry):
+		m.editSelectedEntry()
+	case m.keys.Matches(msg, keymap.AmendEntry):
+		m.startAmending()
+	case m.keys.Matches(msg, keymap.DeleteEntry) && m.focus != focusFooter:
+		m.deleteSelectedEntry()
+	case m.keys.Matches(msg, keymap.Undo):
+		m.undo()
+	case m.keys.Matches(msg, keymap.Redo):
+		m.redo()
+	case m.keys.Matches(msg, keymap.OpenProjects):
+		m.overlay = overlayProjects
 		m.focus = focusProjectTree
-		return keyHandled
-	case "1":
-		m.focus = focusHeader
-		m.textInput.Blur()
-		m.taskTable.Blur()
-		return keyHandled
-	case "2":
-		m.focus = focusStats
-		m.textInput.Blur()
-		m.taskTable.Blur()
-		return keyHandled
-	case "3":
-		m.focus = focusTable
-		m.textInput.Blur()
-		m.taskTable.Focus()
-		return keyHandled
-	case "4":
-		m.focus = focusFooter
-		m.taskTable.Blur()
-		m.textInput.Focus()
-		return keyHandled
+	case m.keys.Matches(msg, keymap.OpenHeatmap):
+		m.openHeatmap()
+	case m.keys.Matches(msg, keymap.OpenCharts):
+		m.openCharts()
+	case m.keys.Matches(msg, keymap.OpenTimesheet):
+		m.openTimesheet()
+	case m.keys.Matches(msg, keymap.RemapProject) && m.focus != focusFooter:
+		m.startRemapFromEntry()
+	case m.keys.Matches(msg, keymap.RemapFromFile):
+		m.startRemapFromFile()
+	case m.keys.Matches(msg, keymap.OpenStandup):
+		m.openStandup()
+	case m.keys.Matches(msg, keymap.UseSuggestion) && m.offersSuggestion():
+		m.useSuggestion()
+	case m.keys.Matches(msg, keymap.DropSuggestion) && m.offersSuggestion():
+		m.dropSuggestion()
+	case m.keys.Matches(msg, keymap.FocusHeader):
+		m.setFocus(focusHeader)
+	case m.keys.Matches(msg, keymap.FocusStats):
+		m.setFocus(focusStats)
+	case m.keys.Matches(msg, keymap.FocusTable):
+		m.setFocus(focusTable)
+	case m.keys.Matches(msg, keymap.FocusFooter):
+		m.setFocus(focusFooter)
+	default:
+		return keyIgnored
 	}
-	return keyIgnored
+	return keyHandled
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.handleWindowSize(msg)
-	case fileChangedMsg:
-		m.handleFileChangedMsg()
+	case storeChangedMsg:
+		m.refresh()
 	case fileErrorMsg:
-	// TODO: handle file watch error
+		slog.Error("File watcher failed", "error", msg.err)
+		m.status.SetError(errorSourceWatch, "Watching %s failed: %v", config.TimeLogFilename, msg.err)
+		return m, nil
+	case watcherPollingMsg:
+		m.status.Append(statusWarn, "File watching unavailable (%v), checking %s every %s",
+			msg.reason, config.TimeLogFilename, watcher.DefaultPollInterval)
+		return m, m.status.ExpireCmd()
+	case editorFinishedMsg:
+		toastID := m.status.toastID
+		m.handleEditorFinished(msg)
+		return m, m.toastCmd(toastID)
+	case toastExpiredMsg:
+		m.status.handleExpired(msg)
+		return m, nil
+	case projectsLoadedMsg:
+		toastID := m.status.toastID
+		m.handleProjectsLoaded(msg)
+		return m, m.toastCmd(toastID)
+	case queueFlushedMsg:
+		toastID := m.status.toastID
+		m.handleQueueFlushed(msg)
+		return m, m.toastCmd(toastID)
+	case queueTickMsg:
+		cmds := []tea.Cmd{queueTickCmd()}
+		if len(m.store.queue.Items()) > 0 {
+			cmds = append(cmds, flushQueueCmd(m.ctx, m.store.queue, m.appConfig))
+		}
+		return m, tea.Batch(cmds...)
+	case apiRequestMsg:
+		m.handleAPIRequest(msg)
+		return m, nil
+	case timesheetSubmittedMsg:
+		m.handleTimesheetSubmitted(msg)
+		return m, m.status.ExpireCmd()
 	case tea.KeyMsg:
+		toastID := m.status.toastID
 		var keyResult keyResult
-		if m.showProjectOverlay {
+		switch m.overlay {
+		case overlayProjects:
 			keyResult = m.handleProjectTreeKeyMsg(msg)
-		} else {
+		case overlayHeatmap:
+			keyResult = m.handleHeatmapKeyMsg(msg)
+		case overlayCharts:
+			keyResult = m.handleChartsKeyMsg(msg)
+		case overlayTimesheet:
+			keyResult = m.handleTimesheetKeyMsg(msg)
+		case overlayRemap:
+			keyResult = m.handleRemapKeyMsg(msg)
+		case overlayStandup:
+			keyResult = m.handleStandupKeyMsg(msg)
+		case overlayHelp:
+			keyResult = m.handleHelpKeyMsg(msg)
+		default:
 			keyResult = m.handleKeyMsg(msg)
 		}
 		switch keyResult {
 		case keyHandled:
-			return m, nil
+			cmds := append(m.pendingCmds, m.toastCmd(toastID))
+			m.pendingCmds = nil
+			return m, tea.Batch(cmds...)
 		case keyExit:
 			m.cancel()
 			return m, func() tea.Msg {
@@ -304,6 +887,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case errMsg:
 		m.err = msg
+		m.status.SetError(errorSourceOther, "%v", msg)
 		return m, nil
 	}
 
@@ -311,11 +895,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-func createHeaderContent() string {
+func (m model) createHeaderContent() string {
 	timeNow := time.Now()
 	_, week := timeNow.ISOWeek()
 	dateAndDay := timeNow.Format("January, 02-01-2006")
-	return fmt.Sprintf("%s (Week %d)", dateAndDay, week)
+	header := fmt.Sprintf("%s (Week %d)", dateAndDay, week)
+	if queued := len(m.store.queue.Items()); queued > 0 {
+		header += lipgloss.NewStyle().Foreground(theme.Active().Warning).
+			Render(fmt.Sprintf("  ⇡ %d timesheet(s) queued", queued))
+	}
+	return header
 }
 
 func (m model) createStatsContent() string {
@@ -327,8 +916,8 @@ func (m model) createStatsContent() string {
 	dailyPercent := m.statsCollection.Daily.Work.Hours() / targetDailyHours
 	weeklyPercent := m.statsCollection.Weekly.Work.Hours() / targetWeeklyHours
 
-	dailyBar := progress.New(progress.WithoutPercentage(), progress.WithWidth(progressBarWidth))
-	weeklyBar := progress.New(progress.WithoutPercentage(), progress.WithWidth(progressBarWidth))
+	dailyBar := newProgressBar(progressBarWidth)
+	weeklyBar := newProgressBar(progressBarWidth)
 
 	leaveTime := timelog.FormatTime(m.statsCollection.ArrivedTime.Add(time.Duration(targetDailyHours * float64(time.Hour))))
 
@@ -340,14 +929,79 @@ func (m model) createStatsContent() string {
 	monthlyStat := colStyle.Render("MONTH " + timelog.FormatStatDuration(m.statsCollection.Monthly.Work))
 
 	divider := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).PaddingRight(1).
+		Foreground(theme.Active().Muted).PaddingRight(1).
 		Render(strings.TrimRight(strings.Repeat("│\n", 2), "\n"))
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, dailyStat, divider, weeklyStat, divider, monthlyStat)
 }
 
+func newProgressBar(width int) progress.Model {
+	t := theme.Active()
+	opts := []progress.Option{progress.WithoutPercentage(), progress.WithWidth(width)}
+	if t.Monochrome {
+		opts = append(opts, progress.WithColorProfile(termenv.Ascii), progress.WithFillCharacters('#', '-'))
+	} else {
+		opts = append(opts, progress.WithGradient(string(t.ProgressStart), string(t.ProgressEnd)))
+	}
+	return progress.New(opts...)
+}
+
+func tableStyles() table.Styles {
+	t := theme.Active()
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.Foreground(t.Accent)
+	styles.Selected = styles.Selected.Foreground(t.SelectedFg).Background(t.SelectedBg)
+	if t.Monochrome {
+		styles.Selected = styles.Selected.Reverse(true)
+	}
+	return styles
+}
+
+func treeStyles() treeview.Styles {
+	t := theme.Active()
+	return treeview.Styles{
+		Selected: lipgloss.NewStyle().Bold(true).Foreground(t.SelectedFg).Background(t.SelectedBg),
+		Muted:    lipgloss.NewStyle().Foreground(t.Muted),
+	}
+}
+
+// paneStyles are the borders of the panes, without colours the focused one
+// is thick
+func paneStyles() layout.Styles {
+	t := theme.Active()
+	focused := lipgloss.RoundedBorder()
+	if t.Monochrome {
+		focused = lipgloss.ThickBorder()
+	}
+	return layout.Styles{
+		Blurred: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(t.BorderBlur),
+		Focused: lipgloss.NewStyle().Border(focused).BorderForeground(t.BorderFocus),
+	}
+}
+
 func (m model) createFooterContent() string {
-	return fmt.Sprintf("%v %s", time.Now().Format("15:04"), m.textInput.View())
+	input := m.textInput
+	warning := ""
+	// the project is only checked once its ": " separator is typed
+	project := timelog.ProjectOf(input.Value())
+	switch status := m.projectIndex.Check(project); status {
+	case chrono.ProjectUnknown, chrono.ProjectIncomplete:
+		input.TextStyle = input.TextStyle.Foreground(theme.Active().Danger)
+		warning = lipgloss.NewStyle().Foreground(theme.Active().Danger).Render(" " + status.String() + " project")
+	case chrono.ProjectInactive:
+		input.TextStyle = input.TextStyle.Foreground(theme.Active().Warning)
+		warning = lipgloss.NewStyle().Foreground(theme.Active().Warning).Render(" closed project")
+	}
+
+	if m.offersSuggestion() {
+		input.Placeholder = m.suggestionPlaceholder()
+	}
+
+	timeStamp := time.Now().Format("15:04")
+	if m.amending != nil {
+		timeStamp = m.amending.EndTime.Format("15:04") + " (edit)"
+	}
+	return fmt.Sprintf("%v%s %s", timeStamp, warning, input.View())
 }
 
 // best way to get const slice/maps in go
@@ -372,16 +1026,16 @@ func getTableCols(width int) []table.Column {
 	return columns
 }
 
-func getTableRows(entries []timelog.Entry) []table.Row {
+func getTableRows(entries []timelog.Entry, day time.Time) []table.Row {
 	rows := make([]table.Row, 0)
 
 	var lastEndTime time.Time
 	for i, entry := range entries {
 		startTime := lastEndTime
 		entryDate := entry.EndTime.Format("2006-01-02")
-		currentDate := time.Now().Format("2006-01-02")
+		currentDate := day.Format("2006-01-02")
 
-		// only show entries for today
+		// only show entries for the selected day
 		if entryDate != currentDate {
 			continue
 		}
@@ -392,20 +1046,25 @@ func getTableRows(entries []timelog.Entry) []table.Row {
 
 		timeRange := fmt.Sprintf("%s - %s", startTime.Format("15:04"), entry.EndTime.Format("15:04"))
 		lastEndTime = entry.EndTime
-		rows = append(rows, table.Row{timelog.FormatDuration(entry.Duration), timeRange, entry.Description})
+		description := entry.Description
+		if entry.Unsaved {
+			description = "[unsaved] " + description
+		}
+		rows = append(rows, table.Row{timelog.FormatDuration(entry.Duration), timeRange, description})
 	}
 
 	return rows
 }
 
-func createBodyContent(width, height int, entries []timelog.Entry) table.Model {
+func createBodyContent(width, height int, entries []timelog.Entry, day time.Time) table.Model {
 	cols := getTableCols(width)
-	rows := getTableRows(entries)
+	rows := getTableRows(entries, day)
 	taskTable := table.New(
 		table.WithColumns(cols),
 		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(height),
+		table.WithStyles(tableStyles()),
 	)
 	return taskTable
 }
@@ -413,12 +1072,14 @@ func createBodyContent(width, height int, entries []timelog.Entry) table.Model {
 func (m model) View() string {
 	// make sure width is not negative
 	availableWidth := max(m.width-2, 1)
+	styles := paneStyles()
 
 	headerPane := layout.Pane{
 		Width:   availableWidth,
 		Title:   "[1]",
-		View:    createHeaderContent,
+		View:    m.createHeaderContent,
 		Focused: m.focus == focusHeader,
+		Styles:  styles,
 	}
 
 	statsPane := layout.Pane{
@@ -426,17 +1087,24 @@ func (m model) View() string {
 		Title:   "[2]",
 		View:    m.createStatsContent,
 		Focused: m.focus == focusStats,
+		Styles:  styles,
 	}
 
-	fixedHeight := HeaderHeight + StatsHeight + FooterHeight + 2
+	fixedHeight := HeaderHeight + StatsHeight + FooterHeight + StatusHeight + 2
 	bodyHeight := max(m.height-fixedHeight, 1)
 
+	bodyTitle := "[3]"
+	if !m.viewDate.IsZero() {
+		bodyTitle += " " + m.viewDate.Format("Mon 02 Jan 2006")
+	}
+
 	bodyPane := layout.Pane{
 		Width:   availableWidth,
-		Title:   "[3]",
+		Title:   bodyTitle,
 		View:    m.taskTable.View,
 		Height:  bodyHeight,
 		Focused: m.focus == focusTable,
+		Styles:  styles,
 	}
 
 	footerPane := layout.Pane{
@@ -444,6 +1112,7 @@ func (m model) View() string {
 		Title:   "[4]",
 		View:    m.createFooterContent,
 		Focused: m.focus == focusFooter,
+		Styles:  styles,
 	}
 
 	mainView := lipgloss.JoinVertical(lipgloss.Left,
@@ -451,72 +1120,124 @@ func (m model) View() string {
 		statsPane.Render(),
 		bodyPane.Render(),
 		footerPane.Render(),
+		m.status.View(m.width),
 	)
 
-	if !m.showProjectOverlay {
+	var overlayPane layout.Pane
+	switch m.overlay {
+	case overlayProjects:
+		overlayPane = layout.Pane{
+			Title:   m.projectsTitle(),
+			Width:   40,
+			Height:  15,
+			View:    m.projectTree.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayHeatmap:
+		overlayPane = layout.Pane{
+			Title:   "Calendar",
+			Width:   heatmapPaneWidth(m.width),
+			View:    m.heatmap.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayHelp:
+		overlayPane = layout.Pane{
+			Title:   "Keys",
+			Width:   m.helpView.Width(),
+			View:    m.helpView.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayCharts:
+		width, height := chartsPaneSize(m.width, m.height)
+		overlayPane = layout.Pane{
+			Title:   "Charts",
+			Width:   width,
+			Height:  height,
+			View:    m.charts.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayTimesheet:
+		width, height := chartsPaneSize(m.width, m.height)
+		overlayPane = layout.Pane{
+			Title:   "Timesheet",
+			Width:   width,
+			Height:  height,
+			View:    m.timesheet.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayRemap:
+		width, height := chartsPaneSize(m.width, m.height)
+		overlayPane = layout.Pane{
+			Title:   "Rename",
+			Width:   width,
+			Height:  height,
+			View:    m.remap.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	case overlayStandup:
+		width, height := chartsPaneSize(m.width, m.height)
+		overlayPane = layout.Pane{
+			Title:   "Standup",
+			Width:   width,
+			Height:  height,
+			View:    m.standup.View,
+			Focused: true,
+			Styles:  styles,
+		}
+	default:
 		return mainView
 	}
 
-	projectPane := layout.Pane{
-		Title:   "Projects",
-		Width:   40,
-		Height:  15,
-		View:    m.projectTree.View,
-		Focused: true,
-	}
+	return overlay.Composite(overlayPane.Render(), mainView, overlay.Center, overlay.Center, 0, 0)
+}
 
-	return overlay.Composite(projectPane.Render(), mainView, overlay.Center, overlay.Center, 0, 0)
+func chartsPaneSize(windowWidth, windowHeight int) (int, int) {
+	return max(min(windowWidth-6, 100), 30), max(windowHeight-6, 8)
 }
 
-type fileChangedMsg struct{}
+func heatmapPaneWidth(windowWidth int) int {
+	// room for 53 weeks of two cells plus weekday labels
+	return max(min(windowWidth-6, 2*heatmapWeeks+4), 20)
+}
 
 type fileErrorMsg struct {
 	err error
 }
 
-// watch modification in ".ttimelog.txt"
-func fileWatcher(ctx context.Context, wg *sync.WaitGroup, program *tea.Program, timeLogFilePath string) error {
-	defer wg.Done()
-
-	slog.Debug("Starting filewatcher on", "filePath", timeLogFilePath)
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err := watcher.Close(); err != nil {
-			slog.Error("Failed to close watcher", "error", err.Error())
-		}
-	}()
+type watcherPollingMsg struct {
+	reason error
+}
 
-	err = watcher.Add(filepath.Dir(timeLogFilePath))
-	if err != nil {
-		return err
-	}
+// watch modification in ".ttimelog.txt" and reload the store, onChange are
+// also called for them
+func fileWatcher(ctx context.Context, wg *sync.WaitGroup, program *programs, st *store, onChange ...func()) error {
+	defer wg.Done()
 
-	for {
-		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return nil
-			}
-			if event.Op&(fsnotify.Write|
-				fsnotify.Create|
-				fsnotify.Rename) != 0 && filepath.Base(event.Name) == config.TimeLogFilename {
-				program.Send(fileChangedMsg{})
+	slog.Debug("Starting filewatcher on", "filePath", st.timeLogFilePath)
+	return watcher.Watch(ctx, st.timeLogFilePath, watcher.Options{
+		OnChange: func() {
+			if err := st.Reload(); err != nil {
+				slog.Error("Failed to load entries on reload", "error", err)
 			}
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return nil
+			for _, notify := range onChange {
+				notify()
 			}
+		},
+		OnError: func(err error) {
 			program.Send(fileErrorMsg{
 				err: err,
 			})
-		case <-ctx.Done():
-			return nil
-		}
-	}
+		},
+		OnPolling: func(reason error) {
+			program.Send(watcherPollingMsg{reason: reason})
+		},
+	})
 }
 
 func main() {
@@ -526,10 +1247,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	args := os.Args[1:]
+	logFlags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if len(args) > 0 {
+		// commands may run next to the TUI, keep its log
+		logFlags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
 	logFilePath := filepath.Join(userDir, config.TimeLogDirname, "ttimelog.log")
 	logFile, err := os.OpenFile(
 		logFilePath,
-		os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		logFlags,
 		0o644,
 	)
 	if err != nil {
@@ -555,29 +1283,136 @@ func main() {
 	appConfig, err := config.LoadConfig(timeLogDirPath)
 	if err != nil {
 		slog.Error("Failed to parse config file", "error", err.Error())
+		fmt.Fprintf(os.Stderr, "Invalid %s: %v\n", config.TimeConfigFile, err)
 		os.Exit(1)
 	}
 
-	err = chrono.FetchProjectList(appConfig)
+	if len(args) > 0 {
+		code := runCommand(appConfig, timeLogFilePath, args)
+		// os.Exit skips the deferred close
+		if err := logFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close log file: %v\n", err)
+		}
+		os.Exit(code)
+	}
+
+	// before the TUI starts, so auth_command can prompt for a passphrase
+	if err := appConfig.ResolveAuthHeader(context.Background()); err != nil {
+		slog.Error("Failed to resolve auth header", "error", err.Error())
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	keys, err := keymap.FromConfig(appConfig)
 	if err != nil {
-		slog.Error("Faield to fetch project list", "error", err.Error())
+		slog.Error("Failed to load keybindings", "error", err.Error())
+		fmt.Fprintf(os.Stderr, "Invalid [keys] in %s: %v\n", config.TimeConfigFile, err)
+		os.Exit(1)
+	}
+
+	activeTheme, themeErr := theme.FromConfig(appConfig, theme.DetectEnvironment())
+	if themeErr != nil {
+		slog.Error("Failed to load theme, falling back to the built-in colours", "error", themeErr.Error())
 	}
+	theme.SetActive(activeTheme)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
 
-	p := tea.NewProgram(initialModel(ctx, cancel, wg, appConfig), tea.WithAltScreen())
+	running := newPrograms()
+	st := newStore(ctx, appConfig, running)
+	initial := initialModel(ctx, cancel, wg, st, keys)
+	if themeErr != nil {
+		initial.status.Append(statusError, "Invalid [theme] in %s, using the built-in colours: %v", config.TimeConfigFile, themeErr)
+	}
+	for _, warning := range appConfig.Warnings {
+		initial.status.Append(statusWarn, "%s", warning)
+	}
+
+	// the API is optional, ttimelog works without it
+	backend := &tuiBackend{}
+	server, err := api.Listen(filepath.Join(timeLogDirPath, config.SocketFile), backend)
+	if err != nil {
+		slog.Error("Failed to start API", "error", err)
+		initial.status.Append(statusWarn, "API not available: %v", err)
+	}
+
+	if appConfig.SSH.Listen != "" {
+		sshServer, err := listenSSH(appConfig, keys, st, running)
+		if err != nil {
+			slog.Error("Failed to start SSH server", "error", err)
+			initial.status.Append(statusWarn, "SSH not available: %v", err)
+		} else {
+			initial.status.Append(statusInfo, "Serving over SSH on %s", sshServer.Addr())
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := sshServer.Serve(ctx); err != nil {
+					slog.Error("SSH server stopped", "error", err)
+				}
+			}()
+		}
+	}
+
+	var board *dashboard.Server
+	var boardListener net.Listener
+	if address := appConfig.Dashboard.Listen; address != "" {
+		boardListener, err = net.Listen("tcp", address)
+		if err != nil {
+			slog.Error("Failed to start dashboard", "error", err)
+			initial.status.Append(statusWarn, "Dashboard not available: %v", err)
+		} else {
+			board = dashboard.New(timeLogFilePath)
+			initial.status.Append(statusInfo, "Dashboard on http://%s", boardListener.Addr())
+		}
+	}
+
+	p := tea.NewProgram(initial, tea.WithAltScreen())
+	backend.program = p
+	running.add(p)
+
+	var onChange []func()
+	if server != nil {
+		onChange = append(onChange, func() {
+			server.Notify(api.Event{Type: api.EventChanged, At: time.Now()})
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Serve(ctx); err != nil {
+				slog.Error("API stopped", "error", err)
+			}
+		}()
+	}
+	if board != nil {
+		onChange = append(onChange, board.Notify)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := board.Serve(ctx, boardListener); err != nil {
+				slog.Error("Dashboard stopped", "error", err)
+			}
+		}()
+	}
 
 	wg.Add(1)
 	go func() {
-		err := fileWatcher(ctx, wg, p, timeLogFilePath)
+		err := fileWatcher(ctx, wg, running, st, onChange...)
 		if err != nil {
 			slog.Error("Failed to start filewatcher", "error", err)
 		}
 	}()
+	wg.Add(1)
+	go func() {
+		if err := suggestionsWatcher(ctx, wg, st, filepath.Join(timeLogDirPath, config.SuggestionsFile)); err != nil {
+			slog.Error("Failed to watch suggestions", "error", err)
+		}
+	}()
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	// let hooks of the last entries finish
+	st.hooks.Wait()
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type periodKind int
+
+const (
+	periodDay periodKind = iota
+	periodWeek
+	periodMonth
+	// periodAll is the whole history, its range is zero
+	periodAll
+	// periodDates runs between explicit dates
+	periodDates
+)
+
+// period is the span of days an overlay shows: the day, week or month
+// around anchor, the whole history or explicit dates
+type period struct {
+	kind   periodKind
+	anchor time.Time // any day inside the period
+	// from and to are the range of periodDates, to excluded
+	from, to time.Time
+}
+
+func newPeriod(kind periodKind, now time.Time) period {
+	return period{kind: kind, anchor: timelog.StartOfDay(now)}
+}
+
+// SetKind switches to the day, week or month around the shown period
+func (p *period) SetKind(kind periodKind) {
+	if p.kind == periodDates {
+		p.anchor = p.from
+	}
+	p.kind = kind
+}
+
+// SetDates shows the days from to to, both included
+func (p *period) SetDates(from, to time.Time) {
+	p.kind = periodDates
+	p.from = timelog.StartOfDay(from)
+	p.to = timelog.StartOfDay(to).AddDate(0, 0, 1)
+}
+
+// Shift moves the period backwards (negative) or forwards in time, explicit
+// dates by their length
+func (p *period) Shift(n int) {
+	switch p.kind {
+	case periodDay:
+		p.anchor = p.anchor.AddDate(0, 0, n)
+	case periodWeek:
+		p.anchor = p.anchor.AddDate(0, 0, 7*n)
+	case periodMonth:
+		from, _ := timelog.MonthRange(p.anchor)
+		p.anchor = from.AddDate(0, n, 0)
+	case periodDates:
+		days := int(p.to.Sub(p.from).Hours()/24 + 0.5)
+		p.from = p.from.AddDate(0, 0, n*days)
+		p.to = p.to.AddDate(0, 0, n*days)
+	}
+}
+
+// Range returns the first day and the day after the last, zero times for
+// the whole history
+func (p period) Range() (time.Time, time.Time) {
+	switch p.kind {
+	case periodDay:
+		return p.anchor, p.anchor.AddDate(0, 0, 1)
+	case periodWeek:
+		return timelog.WeekRange(p.anchor)
+	case periodMonth:
+		return timelog.MonthRange(p.anchor)
+	case periodDates:
+		return p.from, p.to
+	}
+	return time.Time{}, time.Time{}
+}
+
+func (p period) Title() string {
+	from, to := p.Range()
+	switch p.kind {
+	case periodDay:
+		return from.Format("Monday 02 January 2006")
+	case periodWeek:
+		_, week := from.ISOWeek()
+		return fmt.Sprintf("Week %d (%s - %s)", week, from.Format("02 Jan"), to.AddDate(0, 0, -1).Format("02 Jan 2006"))
+	case periodMonth:
+		return from.Format("January 2006")
+	case periodDates:
+		return fmt.Sprintf("%s - %s", from.Format("02 Jan 2006"), to.AddDate(0, 0, -1).Format("02 Jan 2006"))
+	}
+	return "Whole history"
+}
+
+// paneView lays out an overlay: the bold heading, the rows from offset that
+// fit into height and the help line
+func paneView(heading string, rows []string, offset, height int, help string) string {
+	// heading, blank line and help line
+	maxRows := max(height-4, 1)
+	offset = min(offset, max(len(rows)-maxRows, 0))
+	rows = rows[offset:min(offset+maxRows, len(rows))]
+
+	lines := append([]string{lipgloss.NewStyle().Bold(true).Render(heading), ""}, rows...)
+	lines = append(lines, "", lipgloss.NewStyle().Foreground(theme.Active().Muted).Render(help))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodDates(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 4, 0, 0, time.Local)
+	p := newPeriod(periodAll, now)
+	from, to := p.Range()
+	assert.True(t, from.IsZero() && to.IsZero())
+
+	from, to, err := parseDates("2025-03-03 2025-03-05")
+	assert.NoError(t, err)
+	p.SetDates(from, to)
+	from, to = p.Range()
+	assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.Local), from)
+	assert.Equal(t, time.Date(2025, 3, 6, 0, 0, 0, 0, time.Local), to)
+	assert.Equal(t, "03 Mar 2025 - 05 Mar 2025", p.Title())
+
+	// explicit dates move by their length
+	p.Shift(1)
+	assert.Equal(t, "06 Mar 2025 - 08 Mar 2025", p.Title())
+
+	// a week is taken around the first of the dates
+	p.SetKind(periodWeek)
+	assert.Equal(t, "Week 10 (03 Mar - 09 Mar 2025)", p.Title())
+}
+
+func TestParseDates(t *testing.T) {
+	from, to, err := parseDates("2025-03-03")
+	assert.NoError(t, err)
+	assert.Equal(t, from, to)
+
+	_, _, err = parseDates("2025-03-05 2025-03-03")
+	assert.EqualError(t, err, "2025-03-03 is before 2025-03-05")
+
+	_, _, err = parseDates("03/05/2025")
+	assert.EqualError(t, err, `"03/05/2025" is not a YYYY-MM-DD date`)
+
+	_, _, err = parseDates("")
+	assert.Error(t, err)
+}
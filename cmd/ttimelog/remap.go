@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/keymap"
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// remapView previews the lines a project rename rewrites in the timelog
+// file, over the whole history, a week, a month or explicit dates
+type remapView struct {
+	path      string
+	remapping timelog.Remapping
+	period    period
+	changes   []timelog.LineChange
+	err       error
+	// dates takes the first and last day of the period while editingDates
+	dates        textinput.Model
+	editingDates bool
+	offset       int
+	width        int
+	height       int
+	help         string
+}
+
+func newRemapView(path string, remapping timelog.Remapping, now time.Time) remapView {
+	v := remapView{path: path, remapping: remapping, period: newPeriod(periodAll, now)}
+	v.plan()
+	return v
+}
+
+func (v *remapView) plan() {
+	from, to := v.period.Range()
+	v.changes, v.err = timelog.PlanRemap(v.path, v.remapping, from, to)
+	v.offset = 0
+}
+
+func (v *remapView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *remapView) SetPeriod(kind periodKind) {
+	v.period.SetKind(kind)
+	v.plan()
+}
+
+// Shift moves the shown period backwards (negative) or forwards in time
+func (v *remapView) Shift(n int) {
+	if v.period.kind == periodAll {
+		return
+	}
+	v.period.Shift(n)
+	v.plan()
+}
+
+func (v *remapView) Scroll(n int) {
+	v.offset = max(v.offset+n, 0)
+}
+
+// StartDates asks for the first and last day of the period in the pane
+func (v *remapView) StartDates() {
+	v.dates = textinput.New()
+	v.dates.Prompt = "From, to: "
+	v.dates.Placeholder = "YYYY-MM-DD YYYY-MM-DD"
+	v.dates.Focus()
+	v.editingDates = true
+}
+
+// ApplyDates shows the typed dates, the input stays open on an error
+func (v *remapView) ApplyDates() error {
+	from, to, err := parseDates(v.dates.Value())
+	if err != nil {
+		return err
+	}
+	v.editingDates = false
+	v.period.SetDates(from, to)
+	v.plan()
+	return nil
+}
+
+// parseDates parses "YYYY-MM-DD [YYYY-MM-DD]", the first and last day of a
+// period, a single date is one day
+func parseDates(text string) (time.Time, time.Time, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, time.Time{}, errors.New("expected the first and last day as YYYY-MM-DD YYYY-MM-DD")
+	}
+	days := make([]time.Time, 0, 2)
+	for _, field := range fields {
+		day, err := time.ParseInLocation(timelog.DateLayout, field, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date", field)
+		}
+		days = append(days, day)
+	}
+	from, to := days[0], days[len(days)-1]
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s is before %s", fields[1], fields[0])
+	}
+	return from, to, nil
+}
+
+func (v remapView) rows() []string {
+	t := theme.Active()
+	if v.err != nil {
+		return []string{lipgloss.NewStyle().Foreground(t.Danger).Render(v.err.Error())}
+	}
+
+	rows := make([]string, 0, len(v.remapping)+2*len(v.changes)+2)
+	for _, mapping := range v.remapping {
+		rows = append(rows, fmt.Sprintf("%s → %s", mapping.From, mapping.To))
+	}
+	rows = append(rows, "")
+	if len(v.changes) == 0 {
+		return append(rows, "No entries to rename")
+	}
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(max(v.width-2, 10))
+	removed := lineStyle.Foreground(t.Danger)
+	added := lineStyle.Foreground(t.Success)
+	for _, change := range v.changes {
+		rows = append(rows, removed.Render("- "+change.Old), added.Render("+ "+change.New))
+	}
+	return append(rows, "", fmt.Sprintf("%d entries will change", len(v.changes)))
+}
+
+func (v remapView) View() string {
+	help := v.help
+	if v.editingDates {
+		help = v.dates.View()
+	}
+	return paneView("Rename projects — "+v.period.Title(), v.rows(), v.offset, v.height, help)
+}
+
+// startRemapFromEntry opens the project list to pick the new project of the
+// selected entry's project
+func (m *model) startRemapFromEntry() {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		m.status.Toast(statusWarn, "No entry selected")
+		return
+	}
+	project := timelog.ProjectOf(entry.Description)
+	if project == timelog.NoProject {
+		m.status.Toast(statusWarn, "Entry has no project to rename")
+		return
+	}
+	m.remapFrom = project
+	m.overlay = overlayProjects
+	m.focus = focusProjectTree
+}
+
+func (m *model) startRemapFromFile() {
+	path := filepath.Join(m.appConfig.TimeLogDirPath, config.RemapFile)
+	remapping, err := timelog.LoadRemapping(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		m.status.Toast(statusWarn, "Create %s with one \"Old:Path = New:Path\" per line", path)
+		return
+	case err != nil:
+		slog.Error("Failed to load remapping", "filePath", path, "error", err)
+		m.status.Toast(statusError, "Can't read %s: %v", config.RemapFile, err)
+		return
+	case len(remapping) == 0:
+		m.status.Toast(statusWarn, "%s lists no projects to rename", config.RemapFile)
+		return
+	}
+	m.openRemap(remapping)
+}
+
+func (m *model) openRemap(remapping timelog.Remapping) {
+	m.remap = newRemapView(m.timeLogFilePath, remapping, time.Now())
+	m.remap.SetSize(chartsPaneSize(m.width, m.height))
+	m.remap.help = m.keys.ShortHelp(keymap.ContextRemap,
+		keymap.Week, keymap.Month, keymap.All, keymap.Dates, keymap.Left, keymap.Right, keymap.Select)
+	m.overlay = overlayRemap
+	m.focus = focusRemap
+}
+
+func (m *model) handleRemapKeyMsg(msg tea.KeyMsg) keyResult {
+	if m.remap.editingDates {
+		return m.handleRemapDatesKeyMsg(msg)
+	}
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.Week):
+		m.remap.SetPeriod(periodWeek)
+	case m.keys.Matches(msg, keymap.Month):
+		m.remap.SetPeriod(periodMonth)
+	case m.keys.Matches(msg, keymap.All):
+		m.remap.SetPeriod(periodAll)
+	case m.keys.Matches(msg, keymap.Dates):
+		m.remap.StartDates()
+	case m.keys.Matches(msg, keymap.Left):
+		m.remap.Shift(-1)
+	case m.keys.Matches(msg, keymap.Right):
+		m.remap.Shift(1)
+	case m.keys.Matches(msg, keymap.Up):
+		m.remap.Scroll(-1)
+	case m.keys.Matches(msg, keymap.Down):
+		m.remap.Scroll(1)
+	case m.keys.Matches(msg, keymap.Select):
+		m.applyRemap()
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
+	}
+	return keyHandled
+}
+
+// handleRemapDatesKeyMsg types into the dates of the remap preview
+func (m *model) handleRemapDatesKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Submit):
+		if err := m.remap.ApplyDates(); err != nil {
+			m.status.Toast(statusWarn, "%v", err)
+		}
+	case m.keys.Matches(msg, keymap.Close):
+		m.remap.editingDates = false
+	default:
+		m.remap.dates, _ = m.remap.dates.Update(msg)
+	}
+	return keyHandled
+}
+
+func (m *model) applyRemap() {
+	if m.remap.err != nil || len(m.remap.changes) == 0 {
+		m.status.Toast(statusInfo, "Nothing to rename")
+		return
+	}
+	backupPath, err := m.store.Remap(m.remap.changes, time.Now())
+	if errors.Is(err, errUnsavedEntries) {
+		m.status.Toast(statusWarn, "Save the unsaved entries first (%s)", m.keys.Binding(keymap.RetrySave).Help().Key)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to rename projects", "error", err)
+		m.status.Toast(statusError, "Renaming projects failed: %v", err)
+		return
+	}
+	m.refresh()
+	m.closeOverlay()
+	m.status.Toast(statusInfo, "Renamed the project of %d entries, backup in %s", len(m.remap.changes), filepath.Base(backupPath))
+}
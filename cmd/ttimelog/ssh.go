@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/keymap"
+	"github.com/Rash419/ttimelog/internal/sshd"
+	"github.com/Rash419/ttimelog/internal/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// programs are the running interfaces, the local one and one per SSH
+// session. They share the store, which tells all of them about changes
+// made by any.
+type programs struct {
+	mu  sync.Mutex
+	set map[*tea.Program]struct{}
+}
+
+func newPrograms() *programs {
+	return &programs{set: make(map[*tea.Program]struct{})}
+}
+
+func (p *programs) add(program *tea.Program) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set[program] = struct{}{}
+}
+
+func (p *programs) remove(program *tea.Program) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.set, program)
+}
+
+// Send sends msg to all programs
+func (p *programs) Send(msg tea.Msg) {
+	p.mu.Lock()
+	running := make([]*tea.Program, 0, len(p.set))
+	for program := range p.set {
+		running = append(running, program)
+	}
+	p.mu.Unlock()
+
+	// outside the lock, Send blocks while a program is busy
+	for _, program := range running {
+		program.Send(msg)
+	}
+}
+
+// sessionEnviron is the environment of an SSH client as far as it is known
+type sessionEnviron struct {
+	term string
+}
+
+func (e sessionEnviron) Environ() []string {
+	return []string{"TERM=" + e.term}
+}
+
+func (e sessionEnviron) Getenv(key string) string {
+	if key == "TERM" {
+		return e.term
+	}
+	return ""
+}
+
+// listenSSH starts serving the interface over SSH as configured in [ssh],
+// every session runs its own model on the shared store
+func listenSSH(appConfig *config.AppConfig, keys keymap.KeyMap, st *store, running *programs) (*sshd.Server, error) {
+	hostKey, err := sshd.LoadHostKey(appConfig.SSHHostKeyPath())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sshd.LoadAuthorizedKeys(appConfig.SSHAuthorizedKeysPath()); err != nil {
+		// nobody could log in
+		return nil, err
+	}
+
+	return sshd.Listen(appConfig.SSH.Listen, hostKey, appConfig.SSHAuthorizedKeysPath(), func(ctx context.Context, session *sshd.Session) {
+		modelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		m := initialModel(modelCtx, cancel, &sync.WaitGroup{}, st, keys)
+		// copy to the client's clipboard, not the server's terminal
+		m.clipboard = termenv.NewOutput(session, termenv.WithEnvironment(sessionEnviron{term: session.Term}))
+		program := tea.NewProgram(m,
+			tea.WithInput(session),
+			tea.WithOutput(session),
+			tea.WithAltScreen(),
+			tea.WithEnvironment([]string{"TERM=" + session.Term}),
+		)
+		running.add(program)
+		defer running.remove(program)
+
+		go func() {
+			// the terminal size comes from the client, not from the output
+			size := session.Size()
+			program.Send(tea.WindowSizeMsg{Width: size.Width, Height: size.Height})
+			for {
+				select {
+				case size := <-session.Resizes():
+					program.Send(tea.WindowSizeMsg{Width: size.Width, Height: size.Height})
+				case <-ctx.Done():
+					// the client left or the server stops
+					program.Kill()
+					return
+				}
+			}
+		}()
+
+		slog.Info("SSH session started", "user", session.User)
+		if _, err := program.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+			slog.Error("SSH session failed", "user", session.User, "error", err)
+		}
+		slog.Info("SSH session ended", "user", session.User)
+	})
+}
+
+// serve runs without a local interface, serving it over SSH until
+// interrupted, e.g. on an always-on workstation
+func (c *cli) serve(args []string) error {
+	flags := c.flagSet("serve")
+	listen := flags.String("listen", c.appConfig.SSH.Listen, "address to serve on, defaults to listen in [ssh]")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if *listen == "" {
+		return usageError{"no address, set listen in [ssh] of " + config.TimeConfigFile + " or use --listen"}
+	}
+	c.appConfig.SSH.Listen = *listen
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := c.appConfig.ResolveAuthHeader(ctx); err != nil {
+		return err
+	}
+	keys, err := keymap.FromConfig(c.appConfig)
+	if err != nil {
+		return fmt.Errorf("invalid [keys]: %w", err)
+	}
+	// there is no local terminal to ask, sessions get colours on a dark
+	// background unless NO_COLOR is set
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	activeTheme, err := theme.FromConfig(c.appConfig, theme.Environment{NoColor: os.Getenv("NO_COLOR") != "", HasDarkBackground: true})
+	if err != nil {
+		slog.Error("Failed to load theme, falling back to the built-in colours", "error", err)
+		fmt.Fprintf(c.stderr, "Invalid [theme] in %s, using the built-in colours: %v\n", config.TimeConfigFile, err)
+	}
+	theme.SetActive(activeTheme)
+
+	running := newPrograms()
+	st := newStore(ctx, c.appConfig, running)
+	if st.queueErr != nil {
+		fmt.Fprintf(c.stderr, "Queued timesheets unavailable: %v\n", st.queueErr)
+	}
+	server, err := listenSSH(c.appConfig, keys, st, running)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Serving ttimelog on %s, press Ctrl+C to stop\n", server.Addr())
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		if err := fileWatcher(ctx, wg, running, st); err != nil {
+			slog.Error("Failed to start filewatcher", "error", err)
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		if err := suggestionsWatcher(ctx, wg, st, filepath.Join(c.appConfig.TimeLogDirPath, config.SuggestionsFile)); err != nil {
+			slog.Error("Failed to watch suggestions", "error", err)
+		}
+	}()
+	err = server.Serve(ctx)
+	wg.Wait()
+	// let hooks of the last entries finish
+	st.hooks.Wait()
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/keymap"
+	"github.com/Rash419/ttimelog/internal/standup"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// standupView shows what to say at the daily standup, to be copied into a
+// chat
+type standupView struct {
+	text   string
+	offset int
+	width  int
+	height int
+	help   string
+}
+
+func (v *standupView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *standupView) Scroll(n int) {
+	v.offset = max(v.offset+n, 0)
+}
+
+func (v standupView) View() string {
+	lineStyle := lipgloss.NewStyle().MaxWidth(max(v.width-2, 10))
+	heading, body, _ := strings.Cut(strings.TrimSuffix(v.text, "\n"), "\n")
+	rows := strings.Split(body, "\n")
+	for i, row := range rows {
+		rows[i] = lineStyle.Render(row)
+	}
+	return paneView(lineStyle.Render(heading), rows, v.offset, v.height, v.help)
+}
+
+func (m *model) openStandup() {
+	m.standup = standupView{text: standup.New(m.entries, time.Now(), m.appConfig.IsHoliday).Text()}
+	m.standup.SetSize(chartsPaneSize(m.width, m.height))
+	m.standup.help = m.keys.ShortHelp(keymap.ContextStandup, keymap.Copy, keymap.Up, keymap.Down)
+	m.overlay = overlayStandup
+	m.focus = focusStandup
+}
+
+func (m *model) handleStandupKeyMsg(msg tea.KeyMsg) keyResult {
+	switch {
+	case m.keys.Matches(msg, keymap.Quit):
+		return keyExit
+	case m.keys.Matches(msg, keymap.Help):
+		m.openHelp()
+	case m.keys.Matches(msg, keymap.Up):
+		m.standup.Scroll(-1)
+	case m.keys.Matches(msg, keymap.Down):
+		m.standup.Scroll(1)
+	case m.keys.Matches(msg, keymap.Copy):
+		// OSC 52, the terminal puts it on the clipboard of the machine it
+		// runs on, also over SSH. Written here in Update rather than from a
+		// Cmd's goroutine, so it lands between two frames, not inside one.
+		m.clipboard.Copy(m.standup.text)
+		m.status.Toast(statusInfo, "Standup copied to the clipboard")
+	case m.keys.Matches(msg, keymap.Close):
+		m.closeOverlay()
+	}
+	return keyHandled
+}
+
+func (c *cli) standup(args []string) error {
+	flags := c.flagSet("standup")
+	copyText := flags.Bool("copy", false, "also copy to the clipboard of the terminal with OSC 52")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	entries, _, _, err := c.loadEntries()
+	if err != nil {
+		return err
+	}
+	text := standup.New(entries, time.Now(), c.appConfig.IsHoliday).Text()
+	if _, err := fmt.Fprint(c.stdout, text); err != nil {
+		return err
+	}
+	if !*copyText {
+		return nil
+	}
+
+	// the terminal, also when stdout is piped
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to copy with: %w", err)
+	}
+	defer tty.Close()
+	termenv.NewOutput(tty).Copy(text)
+	return nil
+}
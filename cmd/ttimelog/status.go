@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/theme"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const toastDuration = 4 * time.Second
+
+type statusLevel int
+
+const (
+	statusInfo statusLevel = iota
+	statusWarn
+	statusError
+)
+
+// errorSource identifies what a persistent error is about, so that it can
+// be cleared once that part recovers. Lower values are shown first.
+type errorSource int
+
+const (
+	errorSourceSave errorSource = iota
+	errorSourceLoad
+	errorSourceWatch
+	errorSourceOther
+)
+
+type toastExpiredMsg struct {
+	id int
+}
+
+// statusBar is the line below the input showing short lived toasts and
+// errors that stay until the failing operation succeeds.
+type statusBar struct {
+	toast      string
+	toastLevel statusLevel
+	toastID    int
+	errors     map[errorSource]string
+}
+
+// Toast shows a message until it expires, call ExpireCmd to schedule the expiry
+func (s *statusBar) Toast(level statusLevel, format string, args ...any) {
+	s.toastID++
+	s.toast = fmt.Sprintf(format, args...)
+	s.toastLevel = level
+}
+
+// Append adds a message to the current toast instead of replacing it, for
+// the problems found at startup or in the background that nobody asked
+// about. The toast takes the level of the most severe message.
+func (s *statusBar) Append(level statusLevel, format string, args ...any) {
+	if s.toast == "" {
+		s.Toast(level, format, args...)
+		return
+	}
+	s.toastID++
+	s.toast += "; " + fmt.Sprintf(format, args...)
+	s.toastLevel = max(s.toastLevel, level)
+}
+
+// ExpireCmd clears the current toast after toastDuration
+func (s statusBar) ExpireCmd() tea.Cmd {
+	if s.toast == "" {
+		return nil
+	}
+	id := s.toastID
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+func (s *statusBar) handleExpired(msg toastExpiredMsg) {
+	// a newer toast replaced the one that expired
+	if msg.id == s.toastID {
+		s.toast = ""
+	}
+}
+
+func (s *statusBar) SetError(source errorSource, format string, args ...any) {
+	if s.errors == nil {
+		s.errors = make(map[errorSource]string)
+	}
+	s.errors[source] = fmt.Sprintf(format, args...)
+}
+
+func (s *statusBar) ClearError(source errorSource) {
+	delete(s.errors, source)
+}
+
+func (s statusBar) View(width int) string {
+	t := theme.Active()
+	style := lipgloss.NewStyle().MaxWidth(width)
+
+	for source := errorSourceSave; source <= errorSourceOther; source++ {
+		text, ok := s.errors[source]
+		if !ok {
+			continue
+		}
+		if len(s.errors) > 1 {
+			text += fmt.Sprintf(" (+%d more)", len(s.errors)-1)
+		}
+		if t.Monochrome {
+			return style.Render("ERROR " + text)
+		}
+		return style.Foreground(t.Danger).Render("✗ " + text)
+	}
+
+	switch {
+	case s.toast == "":
+		return ""
+	case s.toastLevel == statusWarn:
+		style = style.Foreground(t.Warning)
+	case s.toastLevel == statusError:
+		style = style.Foreground(t.Danger)
+	default:
+		style = style.Foreground(t.Muted)
+	}
+	return style.Render(s.toast)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusAppend(t *testing.T) {
+	var status statusBar
+	status.Append(statusInfo, "Reloaded %s", "ttimelog.txt")
+	status.Append(statusWarn, "File watching unavailable (%v)", "too many open files")
+	status.Append(statusInfo, "Project list updated")
+
+	assert.Equal(t, "Reloaded ttimelog.txt; File watching unavailable (too many open files); Project list updated", status.toast)
+	assert.Equal(t, statusWarn, status.toastLevel)
+
+	// the toast expires as a whole
+	status.handleExpired(toastExpiredMsg{id: status.toastID})
+	assert.Empty(t, status.toast)
+
+	// a toast replaces everything
+	status.Append(statusError, "Editor failed")
+	status.Toast(statusInfo, "Saved %d entries", 2)
+	assert.Equal(t, "Saved 2 entries", status.toast)
+	assert.Equal(t, statusInfo, status.toastLevel)
+}
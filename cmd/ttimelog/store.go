@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/githook"
+	"github.com/Rash419/ttimelog/internal/hooks"
+	"github.com/Rash419/ttimelog/internal/source"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/Rash419/ttimelog/internal/treeview"
+)
+
+var errUnsavedEntries = errors.New("there are unsaved entries")
+
+// store is the timelog state of the process, shared by the local interface
+// and every SSH session. Changes go through it and are announced to all
+// programs, the models render a snapshot and keep only their view state.
+type store struct {
+	mu              sync.Mutex
+	ctx             context.Context
+	appConfig       *config.AppConfig
+	timeLogFilePath string
+	running         *programs
+
+	entries               []timelog.Entry
+	statsCollection       timelog.StatsCollection
+	handledArrivedMessage bool
+	// loadErr is why the timelog file couldn't be read the last time
+	loadErr error
+	journal *timelog.Journal
+	// suggestions are descriptions queued by the git hook, oldest first
+	suggestions []string
+
+	sources      []source.Source
+	projectRoot  *treeview.TreeNode
+	projectIndex chrono.ProjectIndex
+	// projectsFetchedAt is the age of the cached Chronophage project list
+	projectsFetchedAt time.Time
+	// projectsRequested is set once the list is loaded, later sessions
+	// use it instead of loading it again
+	projectsRequested bool
+
+	queue    *chrono.Queue
+	queueErr error
+	// hooks run the user's commands on events
+	hooks *hooks.Runner
+}
+
+// storeChangedMsg tells a program to take a new snapshot of the store
+type storeChangedMsg struct{}
+
+// snapshot is the part of the store a model renders, copied so it can be
+// used without the lock
+type snapshot struct {
+	entries           []timelog.Entry
+	statsCollection   timelog.StatsCollection
+	loadErr           error
+	suggestions       []string
+	projectRoot       *treeview.TreeNode
+	projectIndex      chrono.ProjectIndex
+	projectsFetchedAt time.Time
+}
+
+func newStore(ctx context.Context, appConfig *config.AppConfig, running *programs) *store {
+	timeLogFilePath := filepath.Join(appConfig.TimeLogDirPath, config.TimeLogFilename)
+	s := &store{
+		ctx:             ctx,
+		appConfig:       appConfig,
+		timeLogFilePath: timeLogFilePath,
+		running:         running,
+		journal:         timelog.NewJournal(timeLogFilePath, timelog.DefaultJournalLimit),
+		sources:         source.FromConfig(appConfig),
+		// filled once the sources are loaded by the first model
+		projectRoot: chrono.BuildProjectTree(nil),
+		hooks:       hooks.FromConfig(appConfig),
+	}
+	if err := s.reload(); err != nil {
+		slog.Error("Failed to load entries", "error", err)
+	}
+	s.queue, s.queueErr = chrono.LoadQueue(appConfig.TimeLogDirPath)
+	if s.queueErr != nil {
+		slog.Error("Failed to load submission queue", "error", s.queueErr)
+	}
+	s.loadSuggestions()
+	return s
+}
+
+func (s *store) Snapshot() snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return snapshot{
+		entries:           slices.Clone(s.entries),
+		statsCollection:   s.statsCollection,
+		loadErr:           s.loadErr,
+		suggestions:       slices.Clone(s.suggestions),
+		projectRoot:       s.projectRoot,
+		projectIndex:      s.projectIndex,
+		projectsFetchedAt: s.projectsFetchedAt,
+	}
+}
+
+// notify tells all programs about a change. It doesn't wait for them, the
+// program making the change is busy in Update and can't receive.
+func (s *store) notify() {
+	if s.running != nil {
+		go s.running.Send(storeChangedMsg{})
+	}
+}
+
+// Reload reads the timelog file again after it changed
+func (s *store) Reload() error {
+	s.mu.Lock()
+	err := s.reload()
+	s.mu.Unlock()
+	s.notify()
+	return err
+}
+
+func (s *store) reload() error {
+	entries, statsCollections, handledArrivedMessage, err := timelog.LoadEntries(s.timeLogFilePath)
+	s.loadErr = err
+	if err != nil {
+		return err
+	}
+
+	// entries that failed to save only exist in memory, keep them
+	for _, entry := range s.entries {
+		if entry.Unsaved {
+			entries = append(entries, entry)
+			timelog.UpdateStatsCollection(entry, &statsCollections)
+		}
+	}
+	s.entries = entries
+	s.statsCollection = statsCollections
+	s.handledArrivedMessage = handledArrivedMessage
+	return nil
+}
+
+// reloadAfterWrite reads back a change that was written, a failure shows
+// as loadErr and doesn't undo the change
+func (s *store) reloadAfterWrite() {
+	if err := s.reload(); err != nil {
+		slog.Error("Failed to load entries on reload", "error", err)
+	}
+}
+
+// Log appends an entry ending at now. The entry is kept in memory as
+// unsaved when it can't be written, err tells why.
+func (s *store) Log(description string, now time.Time) (timelog.Entry, error) {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	newEntry, handleArrivedMessage := timelog.LogEntry(s.entries, s.handledArrivedMessage, description, now)
+	if len(s.entries) == 0 || handleArrivedMessage {
+		s.handledArrivedMessage = true
+	}
+
+	var err error
+	if s.hasUnsavedEntries() {
+		// keep the file in order, this entry is written once the earlier ones are
+		newEntry.Unsaved = true
+	} else if err = s.journal.Apply(timelog.Operation{
+		Kind: timelog.OpAppend,
+		Text: timelog.FormatEntry(newEntry, handleArrivedMessage),
+	}); err != nil {
+		slog.Error("Failed to add entry", "description", newEntry.Description, "error", err)
+		newEntry.Unsaved = true
+	}
+	if !newEntry.Unsaved {
+		// an unsaved entry fires its hooks once RetrySaves writes it
+		s.hooks.EntryLogged(s.entries, newEntry, handleArrivedMessage, time.Duration(targetDailyHours*float64(time.Hour)))
+	}
+	s.entries = append(s.entries, newEntry)
+	timelog.UpdateStatsCollection(newEntry, &s.statsCollection)
+	return newEntry, err
+}
+
+func (s *store) hasUnsavedEntries() bool {
+	for _, entry := range s.entries {
+		if entry.Unsaved {
+			return true
+		}
+	}
+	return false
+}
+
+// RetrySaves writes the unsaved entries in order, stopping at the first
+// failure
+func (s *store) RetrySaves() (int, error) {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	saved := 0
+	for i := range s.entries {
+		entry := &s.entries[i]
+		if !entry.Unsaved {
+			continue
+		}
+		startsDay := timelog.IsArrivedMessage(entry.Description)
+		err := s.journal.Apply(timelog.Operation{
+			Kind: timelog.OpAppend,
+			Text: timelog.FormatEntry(*entry, startsDay),
+		})
+		if err != nil {
+			slog.Error("Failed to save entry on retry", "description", entry.Description, "error", err)
+			return saved, err
+		}
+		entry.Unsaved = false
+		s.hooks.EntryLogged(s.entries[:i], *entry, startsDay, time.Duration(targetDailyHours*float64(time.Hour)))
+		saved++
+	}
+	return saved, nil
+}
+
+// Apply performs op on the timelog file through the shared journal, so any
+// session can undo it
+func (s *store) Apply(op timelog.Operation) error {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	if err := s.journal.Apply(op); err != nil {
+		return err
+	}
+	s.reloadAfterWrite()
+	return nil
+}
+
+func (s *store) Undo() (timelog.Operation, error) {
+	return s.step(s.journal.Undo)
+}
+
+func (s *store) Redo() (timelog.Operation, error) {
+	return s.step(s.journal.Redo)
+}
+
+func (s *store) step(perform func() (timelog.Operation, error)) (timelog.Operation, error) {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	op, err := perform()
+	if err != nil {
+		return op, err
+	}
+	s.reloadAfterWrite()
+	return op, nil
+}
+
+// Remap rewrites the project of the changed lines and returns the path of
+// the backup
+func (s *store) Remap(changes []timelog.LineChange, now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	if s.hasUnsavedEntries() {
+		return "", errUnsavedEntries
+	}
+	backupPath, err := timelog.ApplyRemap(s.timeLogFilePath, changes, now)
+	if err != nil {
+		return "", err
+	}
+	// the line based history doesn't apply to the rewritten file
+	s.journal.Reset()
+	s.reloadAfterWrite()
+	return backupPath, nil
+}
+
+func (s *store) suggestionsPath() string {
+	return filepath.Join(s.appConfig.TimeLogDirPath, config.SuggestionsFile)
+}
+
+// LoadSuggestions reads the suggestions again after the git hook added one
+func (s *store) LoadSuggestions() {
+	s.mu.Lock()
+	s.loadSuggestions()
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *store) loadSuggestions() {
+	suggestions, err := githook.LoadSuggestions(s.suggestionsPath())
+	if err != nil {
+		slog.Error("Failed to load suggestions", "error", err)
+		return
+	}
+	s.suggestions = suggestions
+}
+
+// DropSuggestion removes a suggestion once it was used or dismissed
+func (s *store) DropSuggestion(suggestion string) error {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+
+	if i := slices.Index(s.suggestions, suggestion); i >= 0 {
+		s.suggestions = slices.Delete(s.suggestions, i, i+1)
+	}
+	return githook.RemoveSuggestion(s.suggestionsPath(), suggestion)
+}
+
+// LoadProjects loads the project sources, once for all programs unless
+// forced. loaded is false when another program loaded them already.
+func (s *store) LoadProjects(force bool) (loaded bool, err error) {
+	s.mu.Lock()
+	if s.projectsRequested && !force {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.projectsRequested = true
+	s.mu.Unlock()
+
+	// without the lock, a slow server mustn't block the other sessions
+	root, err := source.LoadAll(s.ctx, s.sources, force)
+	fetchedAt := chrono.ProjectListFetchedAt(s.appConfig.TimeLogDirPath)
+
+	s.mu.Lock()
+	changed := !slices.Equal(projectPaths(s.projectRoot), projectPaths(root))
+	s.projectsFetchedAt = fetchedAt
+	// sources that returned nothing have no subtree, the cached lists of
+	// the others still validate their projects
+	if root != nil && len(root.Children) > 0 {
+		s.projectIndex = chrono.NewProjectIndex(root)
+	}
+	s.projectRoot = root
+	s.mu.Unlock()
+
+	// loading the same cached list again isn't a refresh
+	if err == nil && (force || changed) {
+		s.hooks.ProjectsRefreshed(countLeaves(root))
+	}
+	s.notify()
+	return true, err
+}
+
+// projectPaths lists the projects below node in tree order, like
+// countLeaves counts them
+func projectPaths(node *treeview.TreeNode) []string {
+	if node == nil {
+		return nil
+	}
+	if len(node.Children) == 0 {
+		if node.Path == "" {
+			// an empty root
+			return nil
+		}
+		return []string{node.Path}
+	}
+	var paths []string
+	for _, child := range node.Children {
+		paths = append(paths, projectPaths(child)...)
+	}
+	return paths
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreHooksWaitForSave(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, config.HooksDirname), 0o755); err != nil {
+		t.Fatalf("Failed to create hooks dir: %v", err)
+	}
+	// a directory in place of the timelog can't be appended to
+	timeLogFilePath := filepath.Join(dir, config.TimeLogFilename)
+	if err := os.Mkdir(timeLogFilePath, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	appConfig := &config.AppConfig{TimeLogDirPath: dir}
+	appConfig.Hooks.EntryAdded = `echo "$TTIMELOG_EVENT" >> ../events.log`
+	st := newStore(context.Background(), appConfig, nil)
+	eventsPath := filepath.Join(dir, "events.log")
+
+	entry, err := st.Log("Acme: Web: fix login", time.Now())
+	st.hooks.Wait()
+	assert.Error(t, err)
+	assert.True(t, entry.Unsaved)
+	assert.NoFileExists(t, eventsPath)
+
+	// the hooks fire once the entry is written
+	if err := os.Remove(timeLogFilePath); err != nil {
+		t.Fatalf("Failed to remove directory: %v", err)
+	}
+	if err := os.WriteFile(timeLogFilePath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write timelog: %v", err)
+	}
+	saved, err := st.RetrySaves()
+	st.hooks.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved)
+	events, err := os.ReadFile(eventsPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "entry_added\n", string(events))
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/theme"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// timesheetView previews the work of a day or a week per project before it
+// is submitted to Chronophage
+type timesheetView struct {
+	entries   []timelog.Entry
+	period    period
+	submitted map[string]bool
+	// queued are the days waiting in the submission queue
+	queued map[string]bool
+	queue  *chrono.Queue
+	// projects flags logged projects missing from the project list
+	projects chrono.ProjectIndex
+	// checking lists the entries with unknown projects instead
+	checking bool
+	dryRun   bool
+	// submitting is set while a submission is in flight
+	submitting bool
+	offset     int
+	width      int
+	height     int
+	help       string
+}
+
+func newTimesheetView(entries []timelog.Entry, now time.Time, submitted map[string]bool, dryRun bool) timesheetView {
+	if submitted == nil {
+		submitted = make(map[string]bool)
+	}
+	return timesheetView{
+		entries:   entries,
+		period:    newPeriod(periodDay, now),
+		submitted: submitted,
+		queued:    make(map[string]bool),
+		dryRun:    dryRun,
+	}
+}
+
+func (v *timesheetView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+func (v *timesheetView) SetPeriod(kind periodKind) {
+	v.period.SetKind(kind)
+	v.offset = 0
+}
+
+// Shift moves the shown period backwards (negative) or forwards in time
+func (v *timesheetView) Shift(n int) {
+	v.period.Shift(n)
+	v.offset = 0
+}
+
+func (v *timesheetView) ToggleChecking() {
+	v.checking = !v.checking
+	v.offset = 0
+}
+
+func (v *timesheetView) Scroll(n int) {
+	v.offset = max(v.offset+n, 0)
+}
+
+func (v timesheetView) Timesheet() chrono.Timesheet {
+	from, to := v.period.Range()
+	return chrono.BuildTimesheet(v.entries, from, to)
+}
+
+// Pending returns the part of the shown timesheet neither submitted nor
+// queued yet
+func (v timesheetView) Pending() chrono.Timesheet {
+	return v.Timesheet().Without(v.submitted).Without(v.queued)
+}
+
+func (v timesheetView) rows() []string {
+	t := theme.Active()
+	muted := lipgloss.NewStyle().Foreground(t.Muted)
+	sheet := v.Timesheet()
+	if len(sheet.Days) == 0 {
+		return []string{"No work logged"}
+	}
+
+	const valueWidth = len("00h00m")
+	projectWidth := max(v.width/2, 10)
+	projectStyle := lipgloss.NewStyle().Width(projectWidth).MaxWidth(projectWidth)
+
+	rows := make([]string, 0)
+	for _, day := range sheet.Days {
+		date, _ := time.ParseInLocation(timelog.DateLayout, day.Date, time.Local)
+		heading := lipgloss.NewStyle().Bold(true).Render(date.Format("Mon 02 Jan")) + " " + timelog.FormatStatDuration(day.Total())
+		switch {
+		case v.submitted[day.Date]:
+			heading += " " + lipgloss.NewStyle().Foreground(t.Success).Render("submitted")
+		case v.queued[day.Date]:
+			heading += " " + lipgloss.NewStyle().Foreground(t.Warning).Render("queued")
+		}
+		rows = append(rows, heading)
+
+		for _, item := range day.Items {
+			comments := strings.Join(item.Comments, ", ")
+			commentWidth := max(v.width-projectWidth-valueWidth-4, 0)
+			project := projectStyle.Render(truncateLeft(item.Project, projectWidth-1))
+			if status := v.projects.Check(item.Project); status != chrono.ProjectKnown {
+				project = projectStyle.Foreground(t.Danger).Render(truncateLeft(item.Project+" ("+status.String()+")", projectWidth-1))
+			}
+			rows = append(rows, fmt.Sprintf("  %s %*s %s",
+				project,
+				valueWidth, timelog.FormatStatDuration(item.Duration()),
+				muted.Render(lipgloss.NewStyle().MaxWidth(commentWidth).Render(comments))))
+		}
+	}
+
+	summary := "Total " + timelog.FormatStatDuration(sheet.Total())
+	if sheet.Unassigned > 0 {
+		summary += lipgloss.NewStyle().Foreground(t.Warning).
+			Render(fmt.Sprintf(", %s without project won't be submitted", timelog.FormatStatDuration(sheet.Unassigned)))
+	}
+	rows = append(rows, "", summary)
+	return append(rows, v.queueRows()...)
+}
+
+// invalidRows lists the entries of the shown period whose project is not in
+// the project list
+func (v timesheetView) invalidRows() []string {
+	if v.projects.Empty() {
+		return []string{"No project list to check against"}
+	}
+	from, to := v.period.Range()
+	invalid := chrono.CheckEntries(v.entries, from, to, v.projects)
+	if len(invalid) == 0 {
+		return []string{"All projects exist in the project list"}
+	}
+
+	danger := lipgloss.NewStyle().Foreground(theme.Active().Danger)
+	rows := make([]string, 0, len(invalid)+1)
+	rows = append(rows, fmt.Sprintf("%d entries with unknown projects", len(invalid)))
+	for _, item := range invalid {
+		_, description, _ := strings.Cut(item.Entry.Description, ": ")
+		rows = append(rows, fmt.Sprintf("  %s %s %s",
+			item.Entry.EndTime.Format("Mon 02 Jan 15:04"),
+			danger.Render(item.Project+" ("+item.Status.String()+")"),
+			lipgloss.NewStyle().MaxWidth(max(v.width/2, 10)).Render(strings.TrimSpace(description))))
+	}
+	return rows
+}
+
+// queueRows lists the submissions still waiting in the queue
+func (v timesheetView) queueRows() []string {
+	if v.queue == nil {
+		return nil
+	}
+	items := v.queue.Items()
+	if len(items) == 0 {
+		return nil
+	}
+
+	t := theme.Active()
+	muted := lipgloss.NewStyle().Foreground(t.Muted)
+	rows := []string{"", lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Queued submissions (%d)", len(items)))}
+	for _, item := range items {
+		dates := item.Sheet.Dates()
+		period := "empty"
+		if len(dates) > 0 {
+			period = dates[0]
+			if len(dates) > 1 {
+				period += " - " + dates[len(dates)-1]
+			}
+		}
+
+		var state string
+		switch {
+		case item.Rejected:
+			state = lipgloss.NewStyle().Foreground(t.Danger).Render("rejected")
+		case item.Attempts == 0:
+			state = "waiting"
+		default:
+			state = fmt.Sprintf("retry at %s", item.NextTry.Format("15:04"))
+		}
+		rows = append(rows, fmt.Sprintf("  %s %s %s", period, timelog.FormatStatDuration(item.Sheet.Total()), state))
+		if item.LastError != "" {
+			rows = append(rows, "    "+muted.Render(lipgloss.NewStyle().MaxWidth(max(v.width-4, 0)).Render(item.LastError)))
+		}
+	}
+	return rows
+}
+
+func (v timesheetView) View() string {
+	heading := "Timesheet — " + v.period.Title()
+	switch {
+	case v.submitting:
+		heading += " (submitting…)"
+	case v.checking:
+		heading += " (project check)"
+	case v.dryRun:
+		heading += " (dry run)"
+	}
+
+	rows := v.rows()
+	if v.checking {
+		rows = v.invalidRows()
+	}
+	return paneView(heading, rows, v.offset, v.height, v.help)
+}
+
+type timesheetSubmittedMsg struct {
+	sheet chrono.Timesheet
+	err   error
+}
+
+func submitTimesheetCmd(ctx context.Context, appConfig *config.AppConfig, sheet chrono.Timesheet) tea.Cmd {
+	return func() tea.Msg {
+		err := chrono.SubmitTimesheet(ctx, appConfig, sheet)
+		return timesheetSubmittedMsg{sheet: sheet, err: err}
+	}
+}
+
+type queueFlushedMsg struct {
+	sent []chrono.QueuedSubmission
+	err  error
+}
+
+func flushQueueCmd(ctx context.Context, queue *chrono.Queue, appConfig *config.AppConfig) tea.Cmd {
+	return func() tea.Msg {
+		sent, err := queue.Flush(ctx, func(ctx context.Context, sheet chrono.Timesheet) error {
+			return chrono.SubmitTimesheet(ctx, appConfig, sheet)
+		})
+		return queueFlushedMsg{sent: sent, err: err}
+	}
+}
+
+type queueTickMsg struct{}
+
+// queueTickCmd wakes the queue up to retry submissions whose backoff expired
+func queueTickCmd() tea.Cmd {
+	return tea.Tick(chrono.RetryInterval, func(time.Time) tea.Msg {
+		return queueTickMsg{}
+	})
+}
@@ -0,0 +1,79 @@
+// Package api serves a JSON API of the running ttimelog on a Unix socket,
+// for editor plugins and scripts, and provides a client for it.
+//
+// Requests and responses are JSON objects, one per line. A request has an
+// "id", a "method" and its "params"; the response carries the same "id"
+// with a "result" or an "error". After a "subscribe" request the server
+// also writes {"event": {...}} messages on that connection.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+const (
+	MethodAdd       = "add"
+	MethodStats     = "stats"
+	MethodToday     = "today"
+	MethodSubscribe = "subscribe"
+)
+
+// Request is sent by clients
+type Request struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// AddParams are the params of MethodAdd
+type AddParams struct {
+	Text string `json:"text"`
+}
+
+// Message is sent by the server, either a response or an event
+type Message struct {
+	ID     int64           `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Event  *Event          `json:"event,omitempty"`
+}
+
+const (
+	// EventChanged is sent when the timelog file changed, by ttimelog or
+	// anything else
+	EventChanged = "changed"
+)
+
+// Event is pushed to subscribers
+type Event struct {
+	Type string    `json:"type"`
+	At   time.Time `json:"at"`
+}
+
+// Stats is the result of MethodStats
+type Stats struct {
+	Today timelog.Totals `json:"today"`
+	Week  timelog.Totals `json:"week"`
+	Month timelog.Totals `json:"month"`
+	// Arrived and Leave are nil until the day was started
+	Arrived *time.Time `json:"arrived,omitempty"`
+	Leave   *time.Time `json:"leave,omitempty"`
+	// Remaining is the minutes of work left to the daily target
+	Remaining int `json:"remaining"`
+	// Last is the last entry, Elapsed the minutes since it
+	Last    string `json:"last"`
+	Elapsed int    `json:"elapsed"`
+}
+
+// Backend answers the requests, i.e. the running TUI
+type Backend interface {
+	// Add logs text like typing it in the input
+	Add(ctx context.Context, text string) (timelog.ExportedEntry, error)
+	Stats(ctx context.Context) (Stats, error)
+	// Today lists the entries of today, including unsaved ones
+	Today(ctx context.Context) ([]timelog.ExportedEntry, error)
+}
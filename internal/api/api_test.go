@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBackend struct {
+	added []string
+}
+
+func (b *fakeBackend) Add(_ context.Context, text string) (timelog.ExportedEntry, error) {
+	if text == "fail" {
+		return timelog.ExportedEntry{}, errors.New("not saved")
+	}
+	b.added = append(b.added, text)
+	return timelog.ExportedEntry{Task: text, Minutes: 30}, nil
+}
+
+func (b *fakeBackend) Stats(context.Context) (Stats, error) {
+	return Stats{Today: timelog.Totals{Work: 90, Slack: 15}}, nil
+}
+
+func (b *fakeBackend) Today(context.Context) ([]timelog.ExportedEntry, error) {
+	return []timelog.ExportedEntry{{Task: "arrived**"}, {Project: "Acme", Task: "fix bug"}}, nil
+}
+
+func startServer(t *testing.T, backend Backend) (*Server, string) {
+	t.Helper()
+	// t.TempDir can exceed the length limit of socket paths
+	dir, err := os.MkdirTemp("", "ttimelog")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "api.sock")
+
+	server, err := Listen(path, backend)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return server, path
+}
+
+func TestClientCalls(t *testing.T) {
+	backend := &fakeBackend{}
+	_, path := startServer(t, backend)
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	entry, err := client.Add(ctx, "Acme: fix bug")
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme: fix bug", entry.Task)
+	assert.Equal(t, []string{"Acme: fix bug"}, backend.added)
+
+	_, err = client.Add(ctx, "fail")
+	assert.EqualError(t, err, "not saved")
+
+	stats, err := client.Stats(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, timelog.Totals{Work: 90, Slack: 15}, stats.Today)
+
+	entries, err := client.Today(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	err = client.call(ctx, "nope", nil, nil)
+	assert.EqualError(t, err, `unknown method "nope"`)
+}
+
+func TestSubscribe(t *testing.T) {
+	server, path := startServer(t, &fakeBackend{})
+
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	_, err = client.Stats(ctx)
+	assert.ErrorIs(t, err, ErrSubscribed)
+
+	at := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	server.Notify(Event{Type: EventChanged, At: at})
+	select {
+	case event := <-events:
+		assert.Equal(t, EventChanged, event.Type)
+		assert.True(t, at.Equal(event.At))
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event received")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestListenInUse(t *testing.T) {
+	_, path := startServer(t, &fakeBackend{})
+
+	_, err := Listen(path, &fakeBackend{})
+	assert.ErrorIs(t, err, ErrInUse)
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ttimelog")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "api.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("Failed to write stale socket: %v", err)
+	}
+
+	server, err := Listen(path, &fakeBackend{})
+	if assert.NoError(t, err) {
+		_ = server.listener.Close()
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// ErrSubscribed is returned for calls on a client after Subscribe
+var ErrSubscribed = errors.New("client is subscribed to events")
+
+// Client talks to a running ttimelog. Calls are safe for concurrent use.
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+
+	mu         sync.Mutex
+	nextID     int64
+	subscribed bool
+}
+
+// Dial connects to the socket of a running ttimelog
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ttimelog on [%s] with error[%v]", path, err)
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &Client{conn: conn, scanner: scanner}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Add logs text like typing it in the ttimelog input
+func (c *Client) Add(ctx context.Context, text string) (timelog.ExportedEntry, error) {
+	var entry timelog.ExportedEntry
+	err := c.call(ctx, MethodAdd, AddParams{Text: text}, &entry)
+	return entry, err
+}
+
+func (c *Client) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	err := c.call(ctx, MethodStats, nil, &stats)
+	return stats, err
+}
+
+// Today lists the entries of today
+func (c *Client) Today(ctx context.Context) ([]timelog.ExportedEntry, error) {
+	var entries []timelog.ExportedEntry
+	err := c.call(ctx, MethodToday, nil, &entries)
+	return entries, err
+}
+
+// Subscribe dedicates the connection to events, the channel is closed when
+// ctx is done or the connection is lost
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	if err := c.call(ctx, MethodSubscribe, nil, nil); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.subscribed = true
+	c.mu.Unlock()
+
+	events := make(chan Event)
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.Close()
+	})
+	go func() {
+		defer close(events)
+		defer stop()
+		for c.scanner.Scan() {
+			var message Message
+			if err := json.Unmarshal(c.scanner.Bytes(), &message); err != nil || message.Event == nil {
+				continue
+			}
+			select {
+			case events <- *message.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribed {
+		return ErrSubscribed
+	}
+
+	c.nextID++
+	request := Request{ID: c.nextID, Method: method}
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		request.Params = encoded
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(requestTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	defer func() { _ = c.conn.SetDeadline(time.Time{}) }()
+
+	if err := json.NewEncoder(c.conn).Encode(request); err != nil {
+		return fmt.Errorf("failed to send [%s] with error[%v]", method, err)
+	}
+	for c.scanner.Scan() {
+		var message Message
+		if err := json.Unmarshal(c.scanner.Bytes(), &message); err != nil {
+			return fmt.Errorf("invalid response to [%s]: %v", method, err)
+		}
+		if message.Event != nil || message.ID != request.ID {
+			continue
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(message.Result, result)
+	}
+	if err := c.scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read response to [%s] with error[%v]", method, err)
+	}
+	return fmt.Errorf("connection closed before the response to [%s]", method)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// requestTimeout bounds how long the backend may take for a request
+const requestTimeout = 10 * time.Second
+
+// ErrInUse is returned by Listen when another ttimelog serves the socket
+var ErrInUse = errors.New("socket is used by another ttimelog")
+
+// Server serves a Backend on a Unix socket
+type Server struct {
+	path     string
+	backend  Backend
+	listener net.Listener
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// Listen creates the socket at path, readable by the user only. A socket
+// left behind by a crashed instance is replaced.
+func Listen(path string, backend Backend) (*Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s: %w", path, ErrInUse)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket[%s] with error[%v]", path, err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on [%s] with error[%v]", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return &Server{
+		path:        path,
+		backend:     backend,
+		listener:    listener,
+		subscribers: make(map[chan Event]struct{}),
+	}, nil
+}
+
+// Serve accepts connections until ctx is done, then removes the socket
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = s.listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// Notify sends the event to all subscribers, dropping it for those that
+// don't keep up
+func (s *Server) Notify(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+func (s *Server) subscribe() chan Event {
+	events := make(chan Event, 16)
+	s.mu.Lock()
+	s.subscribers[events] = struct{}{}
+	s.mu.Unlock()
+	return events
+}
+
+func (s *Server) unsubscribe(events chan Event) {
+	s.mu.Lock()
+	delete(s.subscribers, events)
+	s.mu.Unlock()
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// unblocks the reader below on shutdown
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	var writeMu sync.Mutex
+	encoder := json.NewEncoder(conn)
+	write := func(message Message) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := encoder.Encode(message); err != nil {
+			slog.Debug("Failed to write API message", "error", err)
+		}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var request Request
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			write(Message{Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		if request.Method == MethodSubscribe {
+			events := s.subscribe()
+			defer s.unsubscribe(events)
+			go func() {
+				for {
+					select {
+					case event := <-events:
+						write(Message{Event: &event})
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			write(Message{ID: request.ID, Result: json.RawMessage("true")})
+			continue
+		}
+
+		write(s.handle(ctx, request))
+	}
+}
+
+func (s *Server) handle(ctx context.Context, request Request) Message {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var result any
+	var err error
+	switch request.Method {
+	case MethodAdd:
+		var params AddParams
+		if err := json.Unmarshal(request.Params, &params); err != nil || params.Text == "" {
+			return Message{ID: request.ID, Error: `add needs {"text": "..."}`}
+		}
+		result, err = s.backend.Add(ctx, params.Text)
+	case MethodStats:
+		result, err = s.backend.Stats(ctx)
+	case MethodToday:
+		result, err = s.backend.Today(ctx)
+	default:
+		return Message{ID: request.ID, Error: fmt.Sprintf("unknown method %q", request.Method)}
+	}
+	if err != nil {
+		return Message{ID: request.ID, Error: err.Error()}
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return Message{ID: request.ID, Error: err.Error()}
+	}
+	return Message{ID: request.ID, Result: encoded}
+}
@@ -1,86 +1,176 @@
 package chrono
 
 import (
-	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
 	"github.com/Rash419/ttimelog/internal/treeview"
 )
 
-func ParseProjectList(filePath string) (*treeview.TreeNode, error) {
-	file, err := os.Open(filePath)
+// DefaultProjectListTTL is how long a downloaded project list is used
+// before asking the server again
+const DefaultProjectListTTL = 12 * time.Hour
+
+// ErrNoTaskListURL is returned when no task_list_url is configured
+var ErrNoTaskListURL = errors.New("task_list_url is not set in the [gtimelog] section")
+
+// ParseProjectList reads the cached project list in the given
+// project_list_format, see ParserFor
+func ParseProjectList(filePath string, format string) (*treeview.TreeNode, error) {
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			slog.Error("Failed to close file", "error", err)
-		}
-	}()
+	projects, err := ParseProjects(format, "", content)
+	if err != nil {
+		return nil, err
+	}
+	return BuildProjectTree(projects), nil
+}
 
-	scanner := bufio.NewScanner(file)
+// projectListMeta is stored next to the project list to revalidate it
+type projectListMeta struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
 
-	hiddenRoot := treeview.TreeNode{
-		Expanded: true,
-		Label: "Projects",
+func loadProjectListMeta(path string) projectListMeta {
+	var meta projectListMeta
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return meta
 	}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		slog.Warn("Ignoring invalid project list metadata", "filePath", path, "error", err)
+		return projectListMeta{}
+	}
+	return meta
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		ignoreLine := line == "" || strings.HasPrefix(line, "#") || strings.Contains(line, "*")
-		if ignoreLine {
-			continue
-		}
-
-		tokens := strings.Split(line, ":")
-		if len(tokens) != 4 {
-			continue
-		}
+func saveProjectListMeta(path string, meta projectListMeta) error {
+	content, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return timelog.WriteFileAtomic(path, content)
+}
 
-		treeview.AppendPath(&hiddenRoot, tokens, 0)
+// ProjectListFetchedAt returns when the cached project list was last
+// downloaded or revalidated, zero when there is none
+func ProjectListFetchedAt(timeLogDir string) time.Time {
+	meta := loadProjectListMeta(filepath.Join(timeLogDir, config.ProjectListMetaFile))
+	if !meta.FetchedAt.IsZero() {
+		return meta.FetchedAt
 	}
-	return &hiddenRoot, nil
+	if info, err := os.Stat(filepath.Join(timeLogDir, config.ProjectListFile)); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
 }
 
-func FetchProjectList(appConfig *config.AppConfig) error {
+// FetchProjectList downloads the project list into project-list.txt and
+// reports whether it changed. A list younger than the configured TTL is not
+// fetched again unless force is set, and an existing list is revalidated with
+// its ETag/Last-Modified. The cached list is only replaced by a successful
+// response that contains projects.
+func FetchProjectList(ctx context.Context, appConfig *config.AppConfig, force bool) (bool, error) {
+	url := appConfig.Gtimelog.TaskListURL
+	if url == "" {
+		return false, ErrNoTaskListURL
+	}
+
+	projectListPath := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile)
+	metaPath := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListMetaFile)
+	meta := loadProjectListMeta(metaPath)
+	_, statErr := os.Stat(projectListPath)
+	cached := statErr == nil
+
+	ttl := appConfig.Gtimelog.ProjectListTTL
+	if ttl <= 0 {
+		ttl = DefaultProjectListTTL
+	}
+	if cached && !force && time.Since(meta.FetchedAt) < ttl {
+		slog.Debug("Project list is fresh, not fetching", "fetchedAt", meta.FetchedAt)
+		return false, nil
+	}
+
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	req, err := http.NewRequest("GET", appConfig.Gtimelog.TaskListURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	req.Header.Add("Authorization", appConfig.Gtimelog.AuthHeader)
-	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", appConfig.Gtimelog.AuthHeader.Reveal())
+	req.Header.Add("Accept", "application/json, text/csv;q=0.9, text/plain;q=0.8")
+	if cached {
+		if meta.ETag != "" {
+			req.Header.Add("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Add("If-Modified-Since", meta.LastModified)
+		}
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil
+		return false, fmt.Errorf("failed to fetch project list from [%s] with error[%v]", url, err)
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
 
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotModified && cached {
+		meta.FetchedAt = time.Now()
+		return false, saveProjectListMeta(metaPath, meta)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("fetching project list failed with status[%s]", resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/html" {
+		// most likely a login page served instead of the list
+		return false, errors.New("fetching project list returned an HTML page instead of projects")
+	}
 
-	projectListPath := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile)
-	projectListFile, err := os.Create(projectListPath)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to create project-list[%s] with error[%v]", projectListPath, err)
+		return false, fmt.Errorf("failed to read project list with error[%v]", err)
 	}
-	_, err = projectListFile.Write(body)
+	projects, err := ParseProjects(appConfig.Gtimelog.ProjectListFormat, resp.Header.Get("Content-Type"), body)
 	if err != nil {
-		return fmt.Errorf("failed to write to project-list[%s] with error[%v]", projectListPath, err)
+		return false, fmt.Errorf("failed to parse fetched project list with error[%v]", err)
+	}
+	if len(projects) == 0 {
+		return false, errors.New("fetched project list contains no projects")
+	}
+
+	if err := timelog.WriteFileAtomic(projectListPath, body); err != nil {
+		return false, fmt.Errorf("failed to write to project-list[%s] with error[%v]", projectListPath, err)
 	}
 
-	return nil
+	meta = projectListMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	if err := saveProjectListMeta(metaPath, meta); err != nil {
+		slog.Error("Failed to save project list metadata", "filePath", metaPath, "error", err)
+	}
+	return true, nil
 }
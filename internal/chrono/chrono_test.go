@@ -0,0 +1,120 @@
+package chrono
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProjectList = `# projects
+Collabora:Internal:General:Meeting
+Acme:Web:Backend:Dev
+Acme:Web:Backend:Closed*
+`
+
+func readProjectList(t *testing.T, appConfig *config.AppConfig) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile))
+	if err != nil {
+		t.Fatalf("Failed to read project list: %v", err)
+	}
+	return string(content)
+}
+
+func TestFetchProjectList(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Token ABCDXYZ", r.Header.Get("Authorization"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(testProjectList))
+	}))
+	defer server.Close()
+
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	appConfig.Gtimelog.TaskListURL = server.URL
+	appConfig.Gtimelog.AuthHeader = "Token ABCDXYZ"
+
+	changed, err := FetchProjectList(context.Background(), appConfig, false)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, testProjectList, readProjectList(t, appConfig))
+
+	root, err := ParseProjectList(filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile), FormatAuto)
+	assert.NoError(t, err)
+	assert.Len(t, root.Children, 2)
+
+	// within the TTL the server is not asked again
+	changed, err = FetchProjectList(context.Background(), appConfig, false)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 1, requests)
+
+	// forced fetches revalidate with the ETag
+	changed, err = FetchProjectList(context.Background(), appConfig, true)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 2, requests)
+
+	// an expired TTL revalidates as well
+	appConfig.Gtimelog.ProjectListTTL = time.Nanosecond
+	changed, err = FetchProjectList(context.Background(), appConfig, false)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, 3, requests)
+}
+
+func TestFetchProjectListKeepsCacheOnErrors(t *testing.T) {
+	responses := map[string]func(w http.ResponseWriter){
+		"unauthorized": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("<html>Please log in</html>"))
+		},
+		"login page": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>Please log in</html>"))
+		},
+		"no projects": func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte("something else entirely\n"))
+		},
+	}
+
+	for name, respond := range responses {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				respond(w)
+			}))
+			defer server.Close()
+
+			appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+			appConfig.Gtimelog.TaskListURL = server.URL
+			projectListPath := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile)
+			if err := os.WriteFile(projectListPath, []byte(testProjectList), 0o644); err != nil {
+				t.Fatalf("Failed to write project list: %v", err)
+			}
+
+			changed, err := FetchProjectList(context.Background(), appConfig, true)
+			assert.Error(t, err)
+			assert.False(t, changed)
+			assert.Equal(t, testProjectList, readProjectList(t, appConfig))
+		})
+	}
+}
+
+func TestFetchProjectListNotConfigured(t *testing.T) {
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	_, err := FetchProjectList(context.Background(), appConfig, false)
+	assert.ErrorIs(t, err, ErrNoTaskListURL)
+}
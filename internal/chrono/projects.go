@@ -0,0 +1,289 @@
+package chrono
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Rash419/ttimelog/internal/treeview"
+)
+
+// Project is a selectable leaf of the project list
+type Project struct {
+	Path        []string
+	Code        string
+	Description string
+	Inactive    bool
+}
+
+// ProjectListParser reads a project list in one format
+type ProjectListParser interface {
+	Parse(r io.Reader) ([]Project, error)
+}
+
+const (
+	FormatAuto = "auto"
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var parsers = map[string]ProjectListParser{
+	FormatText: TextParser{},
+	FormatJSON: JSONParser{},
+	FormatCSV:  CSVParser{},
+}
+
+// ParserFor returns the parser of a project_list_format. For "auto" the
+// format is guessed from the content type and the content itself.
+func ParserFor(format, contentType string, content []byte) (ProjectListParser, error) {
+	if format == "" || format == FormatAuto {
+		format = detectFormat(contentType, content)
+	}
+	parser, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown project_list_format[%s], use %s, %s, %s or %s", format, FormatAuto, FormatText, FormatJSON, FormatCSV)
+	}
+	return parser, nil
+}
+
+func detectFormat(contentType string, content []byte) string {
+	switch mediaType, _, _ := mime.ParseMediaType(contentType); mediaType {
+	case "application/json":
+		return FormatJSON
+	case "text/csv":
+		return FormatCSV
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return FormatJSON
+	}
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if header, err := csv.NewReader(bytes.NewReader(firstLine)).Read(); err == nil && slices.Contains(normalizeHeader(header), "path") {
+		return FormatCSV
+	}
+	return FormatText
+}
+
+// ParseProjects parses content in the given format (or "auto")
+func ParseProjects(format, contentType string, content []byte) ([]Project, error) {
+	parser, err := ParserFor(format, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Parse(bytes.NewReader(content))
+}
+
+func splitPath(path string) []string {
+	tokens := make([]string, 0)
+	for _, token := range strings.Split(path, ":") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// TextParser reads one "Category:Project:...:Task" path per line, of any
+// depth from two levels on. Lines starting with # are comments, a * marks
+// inactive projects.
+type TextParser struct{}
+
+func (TextParser) Parse(r io.Reader) ([]Project, error) {
+	projects := make([]Project, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inactive := strings.Contains(line, "*")
+		path := splitPath(strings.ReplaceAll(line, "*", ""))
+		if len(path) < 2 {
+			// not a path, e.g. a stray line of prose
+			continue
+		}
+		projects = append(projects, Project{Path: path, Inactive: inactive})
+	}
+	return projects, scanner.Err()
+}
+
+// jsonProject accepts both nested ("name" with "children") and flat ("path")
+// entries, "active" defaults to true
+type jsonProject struct {
+	Name        string          `json:"name"`
+	Path        json.RawMessage `json:"path"`
+	Code        string          `json:"code"`
+	Description string          `json:"description"`
+	Active      *bool           `json:"active"`
+	Children    []jsonProject   `json:"children"`
+}
+
+// JSONParser reads an array of projects, or an object with a "projects"
+// array. Projects either nest with "name" and "children" or are flat with a
+// "path" given as "A:B:C" or ["A", "B", "C"].
+type JSONParser struct{}
+
+func (JSONParser) Parse(r io.Reader) ([]Project, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []jsonProject
+	if err := json.Unmarshal(content, &list); err != nil {
+		var wrapped struct {
+			Projects []jsonProject `json:"projects"`
+		}
+		if wrappedErr := json.Unmarshal(content, &wrapped); wrappedErr != nil {
+			return nil, fmt.Errorf("failed to parse JSON project list with error[%v]", err)
+		}
+		list = wrapped.Projects
+	}
+
+	projects := make([]Project, 0)
+	for _, p := range list {
+		if err := flattenJSON(p, nil, false, &projects); err != nil {
+			return nil, err
+		}
+	}
+	return projects, nil
+}
+
+func flattenJSON(p jsonProject, parent []string, parentInactive bool, projects *[]Project) error {
+	path := slices.Clone(parent)
+	if len(p.Path) > 0 {
+		var asList []string
+		var asString string
+		switch {
+		case json.Unmarshal(p.Path, &asList) == nil:
+			path = append(path, asList...)
+		case json.Unmarshal(p.Path, &asString) == nil:
+			path = append(path, splitPath(asString)...)
+		default:
+			return fmt.Errorf("invalid project path[%s]", string(p.Path))
+		}
+	}
+	if p.Name != "" {
+		path = append(path, p.Name)
+	}
+	if len(path) == 0 {
+		return errors.New("project without name or path")
+	}
+
+	inactive := parentInactive || (p.Active != nil && !*p.Active)
+	if len(p.Children) == 0 {
+		*projects = append(*projects, Project{Path: path, Code: p.Code, Description: p.Description, Inactive: inactive})
+		return nil
+	}
+	for _, child := range p.Children {
+		if err := flattenJSON(child, path, inactive, projects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeHeader(header []string) []string {
+	normalized := make([]string, len(header))
+	for i, column := range header {
+		normalized[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+	return normalized
+}
+
+// CSVParser reads a CSV with a header row. A "path" column is required,
+// "code", "description" and "active" are optional.
+type CSVParser struct{}
+
+func (CSVParser) Parse(r io.Reader) ([]Project, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header with error[%v]", err)
+	}
+	columns := make(map[string]int)
+	for i, column := range normalizeHeader(header) {
+		columns[column] = i
+	}
+	if _, ok := columns["path"]; !ok {
+		return nil, errors.New("CSV project list has no path column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	projects := make([]Project, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV project list with error[%v]", err)
+		}
+
+		path := splitPath(field(record, "path"))
+		if len(path) == 0 {
+			continue
+		}
+		project := Project{Path: path, Code: field(record, "code"), Description: field(record, "description")}
+		if active := field(record, "active"); active != "" {
+			isActive, err := strconv.ParseBool(active)
+			if err != nil {
+				return nil, fmt.Errorf("invalid active value[%s] for project[%s]", active, strings.Join(path, ":"))
+			}
+			project.Inactive = !isActive
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// BuildProjectTree arranges projects below a hidden root node. A branch is
+// inactive when all projects below it are.
+func BuildProjectTree(projects []Project) *treeview.TreeNode {
+	hiddenRoot := treeview.TreeNode{
+		Expanded: true,
+		Label:    "Projects",
+	}
+	for _, project := range projects {
+		leaf := treeview.AppendPath(&hiddenRoot, project.Path, 0)
+		leaf.Code = project.Code
+		leaf.Description = project.Description
+		leaf.Inactive = project.Inactive
+	}
+	markInactiveBranches(&hiddenRoot)
+	return &hiddenRoot
+}
+
+func markInactiveBranches(node *treeview.TreeNode) bool {
+	if len(node.Children) == 0 {
+		return node.Inactive
+	}
+	inactive := true
+	for _, child := range node.Children {
+		if !markInactiveBranches(child) {
+			inactive = false
+		}
+	}
+	node.Inactive = inactive
+	return inactive
+}
@@ -0,0 +1,108 @@
+package chrono
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProjects(t *testing.T) {
+	expected := []Project{
+		{Path: []string{"Acme", "Web", "Dev"}, Code: "AC-1", Description: "Website"},
+		{Path: []string{"Acme", "Web", "Legacy", "Fixes"}, Code: "AC-2", Inactive: true},
+		{Path: []string{"Internal", "Meeting"}},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		content     string
+		expected    []Project
+	}{
+		{
+			name: "text",
+			content: `# comment
+Acme:Web:Dev
+Acme:Web:Legacy:Fixes*
+just some prose
+Internal:Meeting
+`,
+			expected: []Project{
+				{Path: []string{"Acme", "Web", "Dev"}},
+				{Path: []string{"Acme", "Web", "Legacy", "Fixes"}, Inactive: true},
+				{Path: []string{"Internal", "Meeting"}},
+			},
+		},
+		{
+			name: "nested json",
+			content: `[
+  {"name": "Acme", "children": [
+    {"name": "Web", "children": [
+      {"name": "Dev", "code": "AC-1", "description": "Website"},
+      {"name": "Legacy", "active": false, "children": [{"name": "Fixes", "code": "AC-2"}]}
+    ]}
+  ]},
+  {"name": "Internal", "children": [{"name": "Meeting"}]}
+]`,
+			expected: expected,
+		},
+		{
+			name:        "flat json",
+			contentType: "application/json; charset=utf-8",
+			content: `{"projects": [
+  {"path": "Acme:Web:Dev", "code": "AC-1", "description": "Website", "active": true},
+  {"path": ["Acme", "Web", "Legacy", "Fixes"], "code": "AC-2", "active": false},
+  {"path": "Internal:Meeting"}
+]}`,
+			expected: expected,
+		},
+		{
+			name: "csv",
+			content: `path,code,description,active
+Acme:Web:Dev,AC-1,Website,true
+Acme:Web:Legacy:Fixes,AC-2,,false
+Internal:Meeting
+`,
+			expected: expected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := ParseProjects(FormatAuto, tt.contentType, []byte(tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, projects)
+		})
+	}
+}
+
+func TestParseProjectsErrors(t *testing.T) {
+	_, err := ParseProjects("yaml", "", []byte("a:b"))
+	assert.Error(t, err)
+
+	_, err = ParseProjects(FormatJSON, "", []byte("not json"))
+	assert.Error(t, err)
+
+	_, err = ParseProjects(FormatCSV, "", []byte("code,description\nAC-1,x\n"))
+	assert.Error(t, err)
+}
+
+func TestBuildProjectTree(t *testing.T) {
+	root := BuildProjectTree([]Project{
+		{Path: []string{"Acme", "Web", "Dev"}, Code: "AC-1"},
+		{Path: []string{"Acme", "Old", "Fixes"}, Inactive: true},
+	})
+
+	acme := root.Children[0]
+	assert.Equal(t, "Acme", acme.Label)
+	assert.False(t, acme.Inactive)
+
+	dev := acme.Children[0].Children[0]
+	assert.Equal(t, "Acme:Web:Dev", dev.Path)
+	assert.Equal(t, "AC-1", dev.Code)
+
+	old := acme.Children[1]
+	assert.Equal(t, "Acme:Old", old.Path)
+	assert.True(t, old.Inactive)
+	assert.True(t, old.Children[0].Inactive)
+}
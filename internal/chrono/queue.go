@@ -0,0 +1,242 @@
+package chrono
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+const (
+	// RetryInterval is how often callers should call Flush
+	RetryInterval = 30 * time.Second
+	minBackoff    = 30 * time.Second
+	maxBackoff    = 30 * time.Minute
+)
+
+// RejectedError is a submission the server refused, retrying it won't help
+type RejectedError struct {
+	Status string
+	Body   string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("timesheet submission rejected with status[%s]: %s", e.Status, e.Body)
+}
+
+// UnrecordedError is a submission the server accepted whose days couldn't
+// be recorded as submitted. It counts as sent, sending it again would
+// submit the days twice.
+type UnrecordedError struct {
+	Dates []string
+	Err   error
+}
+
+func (e *UnrecordedError) Error() string {
+	return fmt.Sprintf("timesheet submitted but its days[%s] weren't recorded with error[%v]", strings.Join(e.Dates, ", "), e.Err)
+}
+
+func (e *UnrecordedError) Unwrap() error {
+	return e.Err
+}
+
+// QueuedSubmission is a timesheet waiting to be submitted
+type QueuedSubmission struct {
+	ID        int64     `json:"id"`
+	Sheet     Timesheet `json:"sheet"`
+	QueuedAt  time.Time `json:"queued_at"`
+	Attempts  int       `json:"attempts"`
+	NextTry   time.Time `json:"next_try"`
+	LastError string    `json:"last_error,omitempty"`
+	// Rejected submissions stay in the queue for the user to look at, but
+	// are not retried
+	Rejected bool `json:"rejected,omitempty"`
+}
+
+// Queue keeps outbound timesheets in a file until they are submitted, so
+// submissions made while offline go out once the server is reachable again.
+// Submissions are sent in the order they were queued.
+type Queue struct {
+	path string
+	now  func() time.Time
+
+	mu    sync.Mutex
+	items []QueuedSubmission
+	// flushing serializes Flush calls
+	flushing sync.Mutex
+}
+
+// LoadQueue reads the queue of the timelog directory. The returned queue is
+// always usable, it is empty when there is none yet or it can't be read.
+func LoadQueue(timeLogDir string) (*Queue, error) {
+	q := &Queue{path: filepath.Join(timeLogDir, config.QueueFile), now: time.Now}
+	content, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return q, err
+	}
+	if err := json.Unmarshal(content, &q.items); err != nil {
+		// keep the broken file around instead of overwriting it
+		broken := q.path + ".broken"
+		if renameErr := os.Rename(q.path, broken); renameErr != nil {
+			slog.Error("Failed to move broken queue aside", "filePath", q.path, "error", renameErr)
+		}
+		q.items = nil
+		return q, fmt.Errorf("failed to parse queue[%s], moved it to [%s], with error[%v]", q.path, broken, err)
+	}
+	return q, nil
+}
+
+func (q *Queue) save() error {
+	content, err := json.MarshalIndent(q.items, "", "  ")
+	if err != nil {
+		return err
+	}
+	return timelog.WriteFileAtomic(q.path, content)
+}
+
+// Items returns a copy of the queued submissions, oldest first
+func (q *Queue) Items() []QueuedSubmission {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.items)
+}
+
+// Dates returns the days of all queued submissions
+func (q *Queue) Dates() map[string]bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dates := make(map[string]bool)
+	for _, item := range q.items {
+		for _, date := range item.Sheet.Dates() {
+			dates[date] = true
+		}
+	}
+	return dates
+}
+
+// Enqueue adds the timesheet to the end of the queue
+func (q *Queue) Enqueue(sheet Timesheet) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	id := now.UnixNano()
+	if len(q.items) > 0 {
+		id = max(id, q.items[len(q.items)-1].ID+1)
+	}
+	q.items = append(q.items, QueuedSubmission{ID: id, Sheet: sheet, QueuedAt: now, NextTry: now})
+	return q.save()
+}
+
+// Remove drops a submission, e.g. a rejected one the user gave up on
+func (q *Queue) Remove(id int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = slices.DeleteFunc(q.items, func(item QueuedSubmission) bool { return item.ID == id })
+	return q.save()
+}
+
+func backoff(attempts int) time.Duration {
+	delay := minBackoff
+	for range attempts - 1 {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// next returns the oldest submission due for sending. A submission waiting
+// for its backoff holds back the ones queued after it.
+func (q *Queue) next() (QueuedSubmission, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range q.items {
+		if item.Rejected {
+			continue
+		}
+		return item, !q.now().Before(item.NextTry)
+	}
+	return QueuedSubmission{}, false
+}
+
+func (q *Queue) update(id int64, apply func(item *QueuedSubmission) bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.items {
+		if q.items[i].ID != id {
+			continue
+		}
+		if remove := apply(&q.items[i]); remove {
+			q.items = slices.Delete(q.items, i, i+1)
+		}
+		break
+	}
+	return q.save()
+}
+
+// Flush sends due submissions in order with submit until the queue is
+// empty or a submission fails. A rejected submission is kept but skipped,
+// an unrecorded one counts as sent and any other failure is retried later
+// with exponential backoff. It returns the submissions that were sent and
+// the errors of the rejected, unrecorded and failed ones.
+func (q *Queue) Flush(ctx context.Context, submit func(context.Context, Timesheet) error) ([]QueuedSubmission, error) {
+	if !q.flushing.TryLock() {
+		return nil, nil
+	}
+	defer q.flushing.Unlock()
+
+	sent := make([]QueuedSubmission, 0)
+	var failures []error
+	for {
+		item, due := q.next()
+		if !due {
+			return sent, errors.Join(failures...)
+		}
+
+		err := submit(ctx, item.Sheet)
+		var rejected *RejectedError
+		var unrecorded *UnrecordedError
+		done := err == nil || errors.As(err, &unrecorded)
+		saveErr := q.update(item.ID, func(queued *QueuedSubmission) bool {
+			if done {
+				return true
+			}
+			queued.Attempts++
+			queued.LastError = err.Error()
+			queued.Rejected = errors.As(err, &rejected)
+			queued.NextTry = q.now().Add(backoff(queued.Attempts))
+			return false
+		})
+		if saveErr != nil {
+			slog.Error("Failed to save submission queue", "filePath", q.path, "error", saveErr)
+		}
+
+		switch {
+		case err == nil:
+			sent = append(sent, item)
+		case unrecorded != nil:
+			slog.Error("Failed to record submitted days", "id", item.ID, "error", err)
+			sent = append(sent, item)
+			failures = append(failures, err)
+		case rejected != nil:
+			slog.Error("Timesheet submission rejected", "id", item.ID, "error", err)
+			failures = append(failures, err)
+		default:
+			return sent, errors.Join(append(failures, err)...)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package chrono
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func sheetFor(date string) Timesheet {
+	return Timesheet{Days: []TimesheetDay{{Date: date, Items: []TimesheetItem{{Project: "Acme:Web:Dev", Minutes: 60}}}}}
+}
+
+// fakeClock lets tests move past the backoff without sleeping
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestQueue(t *testing.T) (*Queue, *fakeClock) {
+	t.Helper()
+	dir := t.TempDir()
+	q, err := LoadQueue(dir)
+	if err != nil {
+		t.Fatalf("Failed to load queue: %v", err)
+	}
+	clock := &fakeClock{now: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)}
+	q.now = clock.Now
+	return q, clock
+}
+
+func TestQueueOrder(t *testing.T) {
+	q, _ := newTestQueue(t)
+	for _, date := range []string{"2025-03-07", "2025-03-10", "2025-03-08"} {
+		assert.NoError(t, q.Enqueue(sheetFor(date)))
+	}
+
+	var order []string
+	sent, err := q.Flush(context.Background(), func(_ context.Context, sheet Timesheet) error {
+		order = append(order, sheet.Dates()[0])
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sent, 3)
+	assert.Equal(t, []string{"2025-03-07", "2025-03-10", "2025-03-08"}, order)
+	assert.Empty(t, q.Items())
+}
+
+func TestQueueRetriesWithBackoff(t *testing.T) {
+	q, clock := newTestQueue(t)
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-07")))
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-10")))
+
+	offline := errors.New("network is unreachable")
+	calls := 0
+	failing := func(context.Context, Timesheet) error {
+		calls++
+		return offline
+	}
+
+	// the first failure stops the flush, later items wait for the first one
+	sent, err := q.Flush(context.Background(), failing)
+	assert.ErrorIs(t, err, offline)
+	assert.Empty(t, sent)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, q.Items()[0].Attempts)
+
+	// not retried before the backoff expired
+	clock.now = clock.now.Add(minBackoff - time.Second)
+	_, err = q.Flush(context.Background(), failing)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	clock.now = clock.now.Add(time.Second)
+	_, err = q.Flush(context.Background(), failing)
+	assert.ErrorIs(t, err, offline)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, clock.now.Add(2*minBackoff), q.Items()[0].NextTry)
+
+	// connectivity returns, everything goes out in order
+	clock.now = clock.now.Add(2 * minBackoff)
+	var order []string
+	sent, err = q.Flush(context.Background(), func(_ context.Context, sheet Timesheet) error {
+		order = append(order, sheet.Dates()[0])
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sent, 2)
+	assert.Equal(t, []string{"2025-03-07", "2025-03-10"}, order)
+}
+
+func TestQueueSkipsRejected(t *testing.T) {
+	q, _ := newTestQueue(t)
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-07")))
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-10")))
+
+	sent, err := q.Flush(context.Background(), func(_ context.Context, sheet Timesheet) error {
+		if sheet.Dates()[0] == "2025-03-07" {
+			return &RejectedError{Status: "400 Bad Request", Body: "unknown project"}
+		}
+		return nil
+	})
+	var rejected *RejectedError
+	assert.ErrorAs(t, err, &rejected)
+	assert.Len(t, sent, 1)
+
+	items := q.Items()
+	assert.Len(t, items, 1)
+	assert.True(t, items[0].Rejected)
+	assert.Contains(t, items[0].LastError, "unknown project")
+
+	assert.NoError(t, q.Remove(items[0].ID))
+	assert.Empty(t, q.Items())
+}
+
+func TestQueuePersists(t *testing.T) {
+	q, _ := newTestQueue(t)
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-07")))
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-10")))
+
+	reloaded, err := LoadQueue(filepath.Dir(q.path))
+	assert.NoError(t, err)
+	assert.Equal(t, q.Items(), reloaded.Items())
+	assert.Equal(t, map[string]bool{"2025-03-07": true, "2025-03-10": true}, reloaded.Dates())
+	assert.Equal(t, time.Hour, reloaded.Items()[0].Sheet.Total())
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, minBackoff, backoff(1))
+	assert.Equal(t, 4*minBackoff, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(20))
+}
+
+func TestQueueCountsUnrecordedAsSent(t *testing.T) {
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	appConfig := testConfig(t, server.URL)
+	// a directory where the record of submitted days goes can't be appended to
+	if err := os.Mkdir(filepath.Join(appConfig.TimeLogDirPath, config.SubmittedFile), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	q, clock := newTestQueue(t)
+	assert.NoError(t, q.Enqueue(sheetFor("2025-03-10")))
+
+	submit := func(ctx context.Context, sheet Timesheet) error {
+		return SubmitTimesheet(ctx, appConfig, sheet)
+	}
+	sent, err := q.Flush(context.Background(), submit)
+	var unrecorded *UnrecordedError
+	assert.ErrorAs(t, err, &unrecorded)
+	assert.Equal(t, []string{"2025-03-10"}, unrecorded.Dates)
+	assert.Len(t, sent, 1)
+	assert.Empty(t, q.Items())
+
+	// nothing is left to send again
+	clock.now = clock.now.Add(24 * time.Hour)
+	sent, err = q.Flush(context.Background(), submit)
+	assert.NoError(t, err)
+	assert.Empty(t, sent)
+	assert.Equal(t, 1, posts)
+}
@@ -0,0 +1,217 @@
+package chrono
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// TimesheetItem is the work logged against one project leaf on one day
+type TimesheetItem struct {
+	Project  string   `json:"project"`
+	Minutes  int      `json:"minutes"`
+	Comments []string `json:"comments,omitempty"`
+}
+
+// Duration is the submitted time, rounded to the minute
+func (i TimesheetItem) Duration() time.Duration {
+	return time.Duration(i.Minutes) * time.Minute
+}
+
+type TimesheetDay struct {
+	Date  string          `json:"date"`
+	Items []TimesheetItem `json:"items"`
+}
+
+func (d TimesheetDay) Total() time.Duration {
+	var total time.Duration
+	for _, item := range d.Items {
+		total += item.Duration()
+	}
+	return total
+}
+
+// Timesheet is the payload POSTed to the timesheet_url
+type Timesheet struct {
+	Days []TimesheetDay `json:"days"`
+	// Unassigned is work logged without a project, it can't be submitted
+	Unassigned time.Duration `json:"-"`
+}
+
+func (t Timesheet) Total() time.Duration {
+	var total time.Duration
+	for _, day := range t.Days {
+		total += day.Total()
+	}
+	return total
+}
+
+func (t Timesheet) Dates() []string {
+	dates := make([]string, 0, len(t.Days))
+	for _, day := range t.Days {
+		dates = append(dates, day.Date)
+	}
+	return dates
+}
+
+// Without returns the timesheet without the given days, e.g. the ones already submitted
+func (t Timesheet) Without(dates map[string]bool) Timesheet {
+	filtered := Timesheet{Days: make([]TimesheetDay, 0, len(t.Days)), Unassigned: t.Unassigned}
+	for _, day := range t.Days {
+		if !dates[day.Date] {
+			filtered.Days = append(filtered.Days, day)
+		}
+	}
+	return filtered
+}
+
+// BuildTimesheet sums the work of the entries ending in [from, to) per day and
+// project. The text after the project becomes the comments of the item.
+func BuildTimesheet(entries []timelog.Entry, from, to time.Time) Timesheet {
+	sheet := Timesheet{Days: make([]TimesheetDay, 0)}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		dayEntries := timelog.EntriesBetween(entries, day, day.AddDate(0, 0, 1))
+
+		comments := make(map[string][]string)
+		for _, entry := range dayEntries {
+			if timelog.IsSlackEntry(entry) || entry.Duration == 0 {
+				continue
+			}
+			project := timelog.ProjectOf(entry.Description)
+			_, comment, _ := strings.Cut(entry.Description, ": ")
+			comment = strings.TrimSpace(comment)
+			if comment != "" && !slices.Contains(comments[project], comment) {
+				comments[project] = append(comments[project], comment)
+			}
+		}
+
+		items := make([]TimesheetItem, 0)
+		for _, total := range timelog.SummarizeBy(dayEntries, timelog.ProjectOf) {
+			if total.Name == timelog.NoProject {
+				sheet.Unassigned += total.Duration
+				continue
+			}
+			items = append(items, TimesheetItem{
+				Project:  total.Name,
+				Minutes:  int(math.Round(total.Duration.Minutes())),
+				Comments: comments[total.Name],
+			})
+		}
+		if len(items) > 0 {
+			sheet.Days = append(sheet.Days, TimesheetDay{Date: timelog.DateKey(day), Items: items})
+		}
+	}
+	return sheet
+}
+
+// SubmitTimesheet POSTs the timesheet as JSON to the timesheet_url and
+// records its days as submitted, an *UnrecordedError when only the record
+// failed. With dry_run set the payload is only logged.
+func SubmitTimesheet(ctx context.Context, appConfig *config.AppConfig, sheet Timesheet) error {
+	if len(sheet.Days) == 0 {
+		return errors.New("nothing to submit")
+	}
+
+	payload, err := json.Marshal(sheet)
+	if err != nil {
+		return fmt.Errorf("failed to encode timesheet with error[%v]", err)
+	}
+	if appConfig.Gtimelog.DryRun {
+		slog.Info("Dry run, not submitting timesheet", "payload", string(payload))
+		return nil
+	}
+
+	url := appConfig.Gtimelog.TimesheetURL
+	if url == "" {
+		return errors.New("timesheet_url is not set in the [gtimelog] section")
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", appConfig.Gtimelog.AuthHeader.Reveal())
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit timesheet to [%s] with error[%v]", url, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if resp.StatusCode >= 500 {
+			// the server is having trouble, worth trying again later
+			return fmt.Errorf("timesheet submission failed with status[%s]: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return &RejectedError{Status: resp.Status, Body: strings.TrimSpace(string(body))}
+	}
+
+	// the server has the timesheet now, a failure here mustn't resend it
+	if err := MarkSubmitted(appConfig.TimeLogDirPath, sheet.Dates()); err != nil {
+		return &UnrecordedError{Dates: sheet.Dates(), Err: err}
+	}
+	return nil
+}
+
+// LoadSubmitted returns the days recorded as submitted, in timelog.DateLayout
+func LoadSubmitted(timeLogDir string) (map[string]bool, error) {
+	submitted := make(map[string]bool)
+	file, err := os.Open(filepath.Join(timeLogDir, config.SubmittedFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return submitted, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Error("Failed to close file", "error", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if date := strings.TrimSpace(scanner.Text()); date != "" {
+			submitted[date] = true
+		}
+	}
+	return submitted, scanner.Err()
+}
+
+// MarkSubmitted appends the days to the record of submitted days
+func MarkSubmitted(timeLogDir string, dates []string) error {
+	path := filepath.Join(timeLogDir, config.SubmittedFile)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open submitted days[%s] with error[%v]", path, err)
+	}
+	if _, err := file.WriteString(strings.Join(dates, "\n") + "\n"); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write submitted days[%s] with error[%v]", path, err)
+	}
+	return file.Close()
+}
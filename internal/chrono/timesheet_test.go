@@ -0,0 +1,117 @@
+package chrono
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntries() []timelog.Entry {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(d, h, m int) time.Time {
+		return day.AddDate(0, 0, d).Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	return []timelog.Entry{
+		timelog.NewEntry(at(0, 9, 0), "arrived**", 0),
+		timelog.NewEntry(at(0, 10, 0), "Collabora:Internal:General:Meeting: standup", time.Hour),
+		timelog.NewEntry(at(0, 10, 30), "lunch**", 30*time.Minute),
+		timelog.NewEntry(at(0, 12, 0), "Acme:Web:Backend:Dev: fix login", 90*time.Minute),
+		timelog.NewEntry(at(0, 12, 20), "Collabora:Internal:General:Meeting: standup", 20*time.Minute),
+		timelog.NewEntry(at(0, 12, 30), "reading mail", 10*time.Minute),
+		timelog.NewEntry(at(1, 9, 0), "arrived**", 0),
+		timelog.NewEntry(at(1, 11, 0), "Acme:Web:Backend:Dev: review", 2*time.Hour),
+	}
+}
+
+func TestBuildTimesheet(t *testing.T) {
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	sheet := BuildTimesheet(testEntries(), from, from.AddDate(0, 0, 7))
+
+	assert.Equal(t, []string{"2025-03-10", "2025-03-11"}, sheet.Dates())
+	assert.Equal(t, []TimesheetItem{
+		{Project: "Acme:Web:Backend:Dev", Minutes: 90, Comments: []string{"fix login"}},
+		{Project: "Collabora:Internal:General:Meeting", Minutes: 80, Comments: []string{"standup"}},
+	}, sheet.Days[0].Items)
+	assert.Equal(t, 10*time.Minute, sheet.Unassigned)
+	assert.Equal(t, 290*time.Minute, sheet.Total())
+
+	remaining := sheet.Without(map[string]bool{"2025-03-10": true})
+	assert.Equal(t, []string{"2025-03-11"}, remaining.Dates())
+}
+
+func testConfig(t *testing.T, url string) *config.AppConfig {
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	appConfig.Gtimelog.TimesheetURL = url
+	appConfig.Gtimelog.AuthHeader = "Token ABCDXYZ"
+	return appConfig
+}
+
+func TestSubmitTimesheet(t *testing.T) {
+	var received Timesheet
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Token ABCDXYZ", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	appConfig := testConfig(t, server.URL)
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	sheet := BuildTimesheet(testEntries(), from, from.AddDate(0, 0, 7))
+
+	assert.NoError(t, SubmitTimesheet(context.Background(), appConfig, sheet))
+	assert.Equal(t, sheet.Dates(), received.Dates())
+	assert.Equal(t, 90, received.Days[0].Items[0].Minutes)
+
+	submitted, err := LoadSubmitted(appConfig.TimeLogDirPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"2025-03-10": true, "2025-03-11": true}, submitted)
+}
+
+func TestSubmitTimesheetRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	appConfig := testConfig(t, server.URL)
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	sheet := BuildTimesheet(testEntries(), from, from.AddDate(0, 0, 1))
+
+	err := SubmitTimesheet(context.Background(), appConfig, sheet)
+	assert.ErrorContains(t, err, "401")
+	assert.ErrorContains(t, err, "invalid token")
+
+	submitted, err := LoadSubmitted(appConfig.TimeLogDirPath)
+	assert.NoError(t, err)
+	assert.Empty(t, submitted)
+}
+
+func TestSubmitTimesheetDryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	appConfig := testConfig(t, server.URL)
+	appConfig.Gtimelog.DryRun = true
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	sheet := BuildTimesheet(testEntries(), from, from.AddDate(0, 0, 1))
+
+	assert.NoError(t, SubmitTimesheet(context.Background(), appConfig, sheet))
+	assert.Equal(t, 0, requests)
+
+	submitted, err := LoadSubmitted(appConfig.TimeLogDirPath)
+	assert.NoError(t, err)
+	assert.Empty(t, submitted)
+}
@@ -0,0 +1,105 @@
+package chrono
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/Rash419/ttimelog/internal/treeview"
+)
+
+// ProjectStatus tells whether a logged project exists in the project list
+type ProjectStatus int
+
+const (
+	ProjectKnown ProjectStatus = iota
+	// ProjectIncomplete is a category or project whose tasks are not given
+	ProjectIncomplete
+	ProjectInactive
+	ProjectUnknown
+)
+
+func (s ProjectStatus) String() string {
+	switch s {
+	case ProjectIncomplete:
+		return "incomplete"
+	case ProjectInactive:
+		return "closed"
+	case ProjectUnknown:
+		return "unknown"
+	}
+	return "known"
+}
+
+// ProjectIndex looks up project paths in a project tree
+type ProjectIndex struct {
+	nodes map[string]*treeview.TreeNode
+}
+
+// NewProjectIndex indexes every node of the tree by its path. Paths listed by
+// several sources count as active when one of them is.
+func NewProjectIndex(root *treeview.TreeNode) ProjectIndex {
+	index := ProjectIndex{nodes: make(map[string]*treeview.TreeNode)}
+	index.add(root)
+	return index
+}
+
+func (p ProjectIndex) add(node *treeview.TreeNode) {
+	if node == nil {
+		return
+	}
+	if node.Path != "" {
+		if existing, ok := p.nodes[node.Path]; !ok || existing.Inactive {
+			p.nodes[node.Path] = node
+		}
+	}
+	for _, child := range node.Children {
+		p.add(child)
+	}
+}
+
+// Empty reports whether there is no project list to check against
+func (p ProjectIndex) Empty() bool {
+	return len(p.nodes) == 0
+}
+
+// Check looks up a project path such as "A:B:C:D". Without a project list
+// every project is known, and so is timelog.NoProject.
+func (p ProjectIndex) Check(project string) ProjectStatus {
+	if p.Empty() || project == timelog.NoProject {
+		return ProjectKnown
+	}
+	node, ok := p.nodes[strings.Join(splitPath(project), ":")]
+	switch {
+	case !ok:
+		return ProjectUnknown
+	case len(node.Children) > 0:
+		return ProjectIncomplete
+	case node.Inactive:
+		return ProjectInactive
+	}
+	return ProjectKnown
+}
+
+// InvalidEntry is a logged entry whose project is not in the project list
+type InvalidEntry struct {
+	Entry   timelog.Entry
+	Project string
+	Status  ProjectStatus
+}
+
+// CheckEntries returns the work entries ending in [from, to) whose project
+// is not a known, active project of the index
+func CheckEntries(entries []timelog.Entry, from, to time.Time, index ProjectIndex) []InvalidEntry {
+	invalid := make([]InvalidEntry, 0)
+	for _, entry := range timelog.EntriesBetween(entries, from, to) {
+		if timelog.IsSlackEntry(entry) {
+			continue
+		}
+		project := timelog.ProjectOf(entry.Description)
+		if status := index.Check(project); status != ProjectKnown {
+			invalid = append(invalid, InvalidEntry{Entry: entry, Project: project, Status: status})
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,60 @@
+package chrono
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/Rash419/ttimelog/internal/treeview"
+	"github.com/stretchr/testify/assert"
+)
+
+func testProjectIndex() ProjectIndex {
+	return NewProjectIndex(BuildProjectTree([]Project{
+		{Path: []string{"Acme", "Web", "Dev"}},
+		{Path: []string{"Acme", "Web", "Legacy"}, Inactive: true},
+		{Path: []string{"Internal", "Meeting"}},
+	}))
+}
+
+func TestProjectIndexCheck(t *testing.T) {
+	index := testProjectIndex()
+
+	assert.Equal(t, ProjectKnown, index.Check("Acme:Web:Dev"))
+	assert.Equal(t, ProjectKnown, index.Check("Acme: Web :Dev"))
+	assert.Equal(t, ProjectKnown, index.Check(timelog.NoProject))
+	assert.Equal(t, ProjectIncomplete, index.Check("Acme:Web"))
+	assert.Equal(t, ProjectInactive, index.Check("Acme:Web:Legacy"))
+	assert.Equal(t, ProjectUnknown, index.Check("Acme:Mobile:Dev"))
+
+	// without a project list nothing can be checked
+	assert.Equal(t, ProjectKnown, NewProjectIndex(BuildProjectTree(nil)).Check("Acme:Mobile:Dev"))
+}
+
+func TestProjectIndexSeveralSources(t *testing.T) {
+	first := BuildProjectTree([]Project{{Path: []string{"Acme", "Dev"}, Inactive: true}})
+	second := BuildProjectTree([]Project{{Path: []string{"Acme", "Dev"}}})
+	root := &treeview.TreeNode{Children: []*treeview.TreeNode{first, second}}
+
+	assert.Equal(t, ProjectKnown, NewProjectIndex(root).Check("Acme:Dev"))
+}
+
+func TestCheckEntries(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	entries := []timelog.Entry{
+		{EndTime: at(9), Description: "**arrived"},
+		{EndTime: at(10), Description: "Acme:Web:Dev: fix bug", Duration: time.Hour},
+		{EndTime: at(11), Description: "Acme:Old:Dev: fix bug", Duration: time.Hour},
+		{EndTime: at(12), Description: "lunch **", Duration: time.Hour},
+		{EndTime: at(13), Description: "reading mail", Duration: time.Hour},
+		{EndTime: at(14), Description: "Acme:Web:Legacy: port", Duration: time.Hour},
+		{EndTime: at(34), Description: "Acme:Old:Dev: next day", Duration: time.Hour},
+	}
+
+	invalid := CheckEntries(entries, day, day.AddDate(0, 0, 1), testProjectIndex())
+	assert.Equal(t, []InvalidEntry{
+		{Entry: entries[2], Project: "Acme:Old:Dev", Status: ProjectUnknown},
+		{Entry: entries[5], Project: "Acme:Web:Legacy", Status: ProjectInactive},
+	}, invalid)
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
@@ -17,6 +20,27 @@ const (
 	TimeLogFile     = "ttimelog.log"
 	TimeConfigFile  = "ttimelogrc"
 	ProjectListFile = "project-list.txt"
+	SubmittedFile   = "submitted.txt"
+	// QueueFile holds timesheets waiting to be submitted
+	QueueFile = "outbox.json"
+	// SocketFile is the Unix socket of the API of a running ttimelog
+	SocketFile = "ttimelog.sock"
+	// RemapFile lists project paths to rename across the timelog
+	RemapFile = "remap.txt"
+	// ProjectListMetaFile keeps the ETag and fetch time of the project list
+	ProjectListMetaFile = "project-list.meta"
+	// SourceCacheFile keeps the last response of a rest source, by its name
+	SourceCacheFile = "source-%s.json"
+	// AuthorizedKeysFile lists the public keys allowed to log in over SSH
+	AuthorizedKeysFile = "authorized_keys"
+	// HostKeyFile is the SSH host key, created on first use
+	HostKeyFile = "ssh_host_ed25519_key"
+	// SuggestionsFile queues entries suggested by the git hook
+	SuggestionsFile = "suggestions.txt"
+	// HooksDirname holds executables run on events, named after the event
+	HooksDirname = "hooks"
+	// TemplatesDirname holds the report templates of "ttimelog report"
+	TemplatesDirname = "templates"
 )
 
 func GetSlogger(logFile *os.File) *slog.Logger {
@@ -58,9 +82,83 @@ func SetupTimeLogDirectory(userDir string) (string, error) {
 
 type AppConfig struct {
 	Gtimelog struct {
-		AuthHeader  string `ini:"auth_header"`
-		TaskListURL string `ini:"task_list_url"`
+		AuthHeader Secret `ini:"auth_header"`
+		// AuthHeaderEnv, AuthHeaderFile and AuthCommand are alternatives to
+		// keeping the auth header in ttimelogrc, see ResolveAuthHeader
+		AuthHeaderEnv  string `ini:"auth_header_env"`
+		AuthHeaderFile string `ini:"auth_header_file"`
+		AuthCommand    string `ini:"auth_command"`
+		TaskListURL    string `ini:"task_list_url"`
+		// ProjectListTTL is how long the downloaded project list is used, e.g. "12h"
+		ProjectListTTL time.Duration `ini:"project_list_ttl"`
+		// ProjectListFormat is auto, text, json or csv
+		ProjectListFormat string `ini:"project_list_format"`
+		// TimesheetURL receives the submitted timesheets as JSON
+		TimesheetURL string `ini:"timesheet_url"`
+		// DryRun logs timesheets instead of submitting them
+		DryRun bool `ini:"dry_run"`
 	} `ini:"gtimelog"`
+	Calendar struct {
+		// Holidays are days off in YYYY-MM-DD format, separated by commas
+		Holidays []string `ini:"holidays" delim:","`
+	} `ini:"calendar"`
+	Theme struct {
+		// Name is one of the built-in themes or "auto" to follow the terminal background
+		Name          string `ini:"name"`
+		BorderBlur    string `ini:"border_blur"`
+		BorderFocus   string `ini:"border_focus"`
+		Muted         string `ini:"muted"`
+		Accent        string `ini:"accent"`
+		SelectedFg    string `ini:"selected_fg"`
+		SelectedBg    string `ini:"selected_bg"`
+		Success       string `ini:"success"`
+		Warning       string `ini:"warning"`
+		Danger        string `ini:"danger"`
+		ProgressStart string `ini:"progress_start"`
+		ProgressEnd   string `ini:"progress_end"`
+	} `ini:"theme"`
+	StatusLine struct {
+		// Format is the Go template of "ttimelog status --line"
+		Format string `ini:"format"`
+	} `ini:"statusline"`
+	Dashboard struct {
+		// Listen is the localhost address of the web dashboard, e.g.
+		// "127.0.0.1:8741", empty disables it
+		Listen string `ini:"listen"`
+	} `ini:"dashboard"`
+	SSH struct {
+		// Listen is the address serving the interface over SSH, e.g.
+		// ":2222", empty disables it
+		Listen string `ini:"listen"`
+		// AuthorizedKeys and HostKey default to files in ~/.ttimelog,
+		// relative paths are resolved against it
+		AuthorizedKeys string `ini:"authorized_keys"`
+		HostKey        string `ini:"host_key"`
+	} `ini:"ssh"`
+	Git struct {
+		// Mode is what the post-commit hook does with a commit, GitSuggest
+		// or GitLog
+		Mode string `ini:"mode"`
+	} `ini:"git"`
+	Hooks struct {
+		// Timeout bounds every hook, 10s when not set
+		Timeout time.Duration `ini:"timeout"`
+		// commands run with sh -c on the events of the same name, next to
+		// the executables in ~/.ttimelog/hooks
+		EntryAdded        string `ini:"entry_added"`
+		Arrived           string `ini:"arrived"`
+		TargetReached     string `ini:"target_reached"`
+		ProjectsRefreshed string `ini:"projects_refreshed"`
+	} `ini:"hooks"`
+	// GitProjects maps repository names to project paths, read from the
+	// [git.projects] section
+	GitProjects map[string]string `ini:"-"`
+	// Keys maps actions to comma separated keys, read from the [keys] section
+	Keys map[string]string `ini:"-"`
+	// Sources are the [source.<name>] sections, in file order
+	Sources []SourceConfig `ini:"-"`
+	// Warnings are problems with the config that don't prevent starting
+	Warnings       []string `ini:"-"`
 	TimeLogDirPath string
 }
 
@@ -75,6 +173,162 @@ func LoadConfig(timeLogDir string) (*AppConfig, error) {
 	if err := iniCfg.MapTo(&cfg); err != nil {
 		return nil, err
 	}
+	if section, err := iniCfg.GetSection("keys"); err == nil {
+		cfg.Keys = section.KeysHash()
+	}
+	if section, err := iniCfg.GetSection("git.projects"); err == nil {
+		cfg.GitProjects = section.KeysHash()
+	}
+	switch cfg.Git.Mode {
+	case "":
+		cfg.Git.Mode = GitSuggest
+	case GitSuggest, GitLog:
+	default:
+		return nil, fmt.Errorf("unknown git mode[%s], use %s or %s", cfg.Git.Mode, GitSuggest, GitLog)
+	}
+
+	for _, holiday := range cfg.Calendar.Holidays {
+		if _, err := time.Parse(holidayLayout, holiday); err != nil {
+			return nil, fmt.Errorf("invalid holiday[%s] with error[%v]", holiday, err)
+		}
+	}
+	if err := checkLoopback(cfg.Dashboard.Listen); err != nil {
+		return nil, err
+	}
+	sources, err := loadSources(iniCfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Sources = sources
+
+	if err := checkConfigPermissions(configPath, &cfg); err != nil {
+		return nil, err
+	}
 	cfg.TimeLogDirPath = timeLogDir
 	return &cfg, nil
 }
+
+const (
+	// GitSuggest offers commits in the input the next time an entry is logged
+	GitSuggest = "suggest"
+	// GitLog logs commits right away
+	GitLog = "log"
+)
+
+const (
+	SourceFile        = "file"
+	SourceChronophage = "chronophage"
+	SourceREST        = "rest"
+)
+
+// SourceConfig is a [source.<name>] section describing where projects come from
+type SourceConfig struct {
+	Name string `ini:"-"`
+	// Type is file, chronophage or rest
+	Type string `ini:"type"`
+	// Label is the root of the source's projects in the tree, defaults to Name
+	Label string `ini:"label"`
+	// Path and Format are used by file sources
+	Path   string `ini:"path"`
+	Format string `ini:"format"`
+	// URL and the auth header settings are used by rest sources
+	URL            string `ini:"url"`
+	AuthHeader     Secret `ini:"auth_header"`
+	AuthHeaderEnv  string `ini:"auth_header_env"`
+	AuthHeaderFile string `ini:"auth_header_file"`
+	AuthCommand    string `ini:"auth_command"`
+	// Items is the dotted path to the array of projects in the response,
+	// empty when the response is the array
+	Items string `ini:"items"`
+	// PathFields are the dotted fields making up the levels of a project path
+	PathFields       []string `ini:"path_fields" delim:","`
+	PathSeparator    string   `ini:"path_separator"`
+	CodeField        string   `ini:"code_field"`
+	DescriptionField string   `ini:"description_field"`
+	ActiveField      string   `ini:"active_field"`
+}
+
+func loadSources(iniCfg *ini.File) ([]SourceConfig, error) {
+	sources := make([]SourceConfig, 0)
+	for _, section := range iniCfg.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), "source.")
+		if !ok {
+			continue
+		}
+
+		source := SourceConfig{Name: name}
+		if err := section.MapTo(&source); err != nil {
+			return nil, fmt.Errorf("invalid [%s] with error[%v]", section.Name(), err)
+		}
+		if source.Label == "" {
+			source.Label = name
+		}
+		for i, field := range source.PathFields {
+			source.PathFields[i] = strings.TrimSpace(field)
+		}
+
+		switch {
+		case source.Type == SourceFile && source.Path == "":
+			return nil, fmt.Errorf("[%s] needs a path", section.Name())
+		case source.Type == SourceREST && (source.URL == "" || len(source.PathFields) == 0):
+			return nil, fmt.Errorf("[%s] needs a url and path_fields", section.Name())
+		case source.Type != SourceFile && source.Type != SourceChronophage && source.Type != SourceREST:
+			return nil, fmt.Errorf("unknown type[%s] in [%s], use %s, %s or %s", source.Type, section.Name(), SourceFile, SourceChronophage, SourceREST)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// checkLoopback rejects dashboard addresses reachable from other machines,
+// the dashboard has no authentication
+func checkLoopback(address string) error {
+	if address == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid dashboard listen[%s] with error[%v]", address, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("dashboard listen[%s] is not a localhost address", address)
+	}
+	return nil
+}
+
+// SSHAuthorizedKeysPath is the authorized_keys file for the SSH server
+func (c *AppConfig) SSHAuthorizedKeysPath() string {
+	return c.resolvePath(c.SSH.AuthorizedKeys, AuthorizedKeysFile)
+}
+
+// SSHHostKeyPath is the host key file of the SSH server
+func (c *AppConfig) SSHHostKeyPath() string {
+	return c.resolvePath(c.SSH.HostKey, HostKeyFile)
+}
+
+func (c *AppConfig) resolvePath(path, fallback string) string {
+	if path == "" {
+		path = fallback
+	}
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.TimeLogDirPath, path)
+	}
+	return path
+}
+
+const holidayLayout = "2006-01-02"
+
+// IsHoliday reports whether the given day is listed in the [calendar] holidays
+func (c *AppConfig) IsHoliday(day time.Time) bool {
+	date := day.Format(holidayLayout)
+	for _, holiday := range c.Calendar.Holidays {
+		if holiday == date {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -41,5 +42,156 @@ auth_header = Token ABCDXYZ
 	}
 
 	assert.Equal(t, "https://chronophage/rest-api/proxy/tasks", appConfig.Gtimelog.TaskListURL)
-	assert.Equal(t, "Token ABCDXYZ", appConfig.Gtimelog.AuthHeader)
+	assert.Equal(t, "Token ABCDXYZ", appConfig.Gtimelog.AuthHeader.Reveal())
+}
+
+func TestLoadConfigProjectListTTL(t *testing.T) {
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[gtimelog]\nproject_list_ttl = 2h30m\n"), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	appConfig, err := LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 150*time.Minute, appConfig.Gtimelog.ProjectListTTL)
+
+	// unset, the default of the project list applies
+	err = os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[gtimelog]\n"), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	appConfig, err = LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Zero(t, appConfig.Gtimelog.ProjectListTTL)
+}
+
+func TestLoadConfigHolidays(t *testing.T) {
+	testConfig := `
+[calendar]
+holidays = 2025-12-25, 2026-01-01
+`
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte(testConfig), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	appConfig, err := LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2025-12-25", "2026-01-01"}, appConfig.Calendar.Holidays)
+	assert.True(t, appConfig.IsHoliday(time.Date(2026, 1, 1, 15, 0, 0, 0, time.Local)))
+	assert.False(t, appConfig.IsHoliday(time.Date(2026, 1, 2, 15, 0, 0, 0, time.Local)))
+
+	err = os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[calendar]\nholidays = 25/12/2025\n"), 0o666)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	_, err = LoadConfig(tempDir)
+	assert.Error(t, err)
+}
+
+func TestLoadConfigDashboard(t *testing.T) {
+	tempDir := t.TempDir()
+	for listen, valid := range map[string]bool{
+		"127.0.0.1:8741": true,
+		"localhost:8741": true,
+		"[::1]:8741":     true,
+		"0.0.0.0:8741":   false,
+		":8741":          false,
+		"192.168.1.2:80": false,
+		"8741":           false,
+	} {
+		err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[dashboard]\nlisten = "+listen+"\n"), 0o600)
+		if err != nil {
+			t.Fatalf("Failed to write temp file: %v", err)
+		}
+		appConfig, err := LoadConfig(tempDir)
+		if valid {
+			if assert.NoError(t, err, listen) {
+				assert.Equal(t, listen, appConfig.Dashboard.Listen)
+			}
+		} else {
+			assert.Error(t, err, listen)
+		}
+	}
+}
+
+func TestLoadConfigGit(t *testing.T) {
+	testConfig := `
+[git]
+mode = log
+
+[git.projects]
+webshop = Acme:Web:Dev
+`
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte(testConfig), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	appConfig, err := LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, GitLog, appConfig.Git.Mode)
+	assert.Equal(t, map[string]string{"webshop": "Acme:Web:Dev"}, appConfig.GitProjects)
+
+	err = os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[git]\nmode = commit\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	_, err = LoadConfig(tempDir)
+	assert.Error(t, err)
+}
+
+func TestLoadConfigHooks(t *testing.T) {
+	testConfig := `
+[hooks]
+timeout = 30s
+target_reached = notify-send "Target reached"
+`
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte(testConfig), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	appConfig, err := LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, appConfig.Hooks.Timeout)
+	assert.Equal(t, `notify-send "Target reached"`, appConfig.Hooks.TargetReached)
+	assert.Empty(t, appConfig.Hooks.EntryAdded)
+}
+
+func TestLoadConfigSources(t *testing.T) {
+	testConfig := `
+[source.chronophage]
+type = chronophage
+
+[source.jira]
+type = rest
+label = Jira issues
+url = https://jira.example.com/rest/api/2/search
+items = issues
+path_fields = fields.project.name, key
+`
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte(testConfig), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	appConfig, err := LoadConfig(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, appConfig.Sources, 2)
+	assert.Equal(t, "chronophage", appConfig.Sources[0].Label)
+	assert.Equal(t, "Jira issues", appConfig.Sources[1].Label)
+	assert.Equal(t, []string{"fields.project.name", "key"}, appConfig.Sources[1].PathFields)
+
+	err = os.WriteFile(filepath.Join(tempDir, TimeConfigFile), []byte("[source.broken]\ntype = rest\n"), 0o600)
+	if err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	_, err = LoadConfig(tempDir)
+	assert.Error(t, err)
 }
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Secret is a credential that is redacted when printed or logged, use Reveal
+// to get the value
+type Secret string
+
+const redacted = "[redacted]"
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// LogValue keeps secrets out of ttimelog.log
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+const authCommandTimeout = 30 * time.Second
+
+// ResolveAuthHeader fills the auth header of [gtimelog] and of every
+// [source.*] from the first configured out of auth_header_env,
+// auth_header_file and auth_command, keeping the plain auth_header when none
+// is set.
+func (c *AppConfig) ResolveAuthHeader(ctx context.Context) error {
+	gtimelog := &c.Gtimelog
+	header, err := resolveSecret(ctx, gtimelog.AuthHeader, gtimelog.AuthHeaderEnv, gtimelog.AuthHeaderFile, gtimelog.AuthCommand)
+	if err != nil {
+		return err
+	}
+	gtimelog.AuthHeader = header
+
+	for i := range c.Sources {
+		source := &c.Sources[i]
+		header, err := resolveSecret(ctx, source.AuthHeader, source.AuthHeaderEnv, source.AuthHeaderFile, source.AuthCommand)
+		if err != nil {
+			return fmt.Errorf("[source.%s]: %w", source.Name, err)
+		}
+		source.AuthHeader = header
+	}
+	return nil
+}
+
+func resolveSecret(ctx context.Context, plain Secret, env, file, command string) (Secret, error) {
+	var (
+		value  string
+		source string
+		err    error
+	)
+	switch {
+	case env != "":
+		source = "auth_header_env"
+		value = os.Getenv(env)
+		if value == "" {
+			err = fmt.Errorf("environment variable[%s] is not set", env)
+		}
+	case file != "":
+		source = "auth_header_file"
+		value, err = readSecretFile(expandHome(file))
+	case command != "":
+		source = "auth_command"
+		value, err = runAuthCommand(ctx, command)
+	default:
+		return plain, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get auth header from %s with error[%v]", source, err)
+	}
+	if plain != "" {
+		slog.Warn("Ignoring auth_header in favour of another auth source", "source", source)
+	}
+
+	secret := Secret(strings.TrimSpace(value))
+	slog.Debug("Resolved auth header", "source", source, "authHeader", secret)
+	return secret, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// readSecretFile refuses files that other users can read
+func readSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("file[%s] has mode %04o, make it private with chmod 600", path, info.Mode().Perm())
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// runAuthCommand runs the command with sh and returns its stdout. stderr
+// goes to the terminal, as tools like pass may ask for a passphrase there.
+func runAuthCommand(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, authCommandTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("command timed out after %s", authCommandTimeout)
+		}
+		return "", err
+	}
+	if strings.TrimSpace(stdout.String()) == "" {
+		return "", errors.New("command printed nothing")
+	}
+	return stdout.String(), nil
+}
+
+// checkConfigPermissions warns when a config file holding a secret can be
+// read by other users. It doesn't refuse to start, so upgrading doesn't lock
+// existing users out.
+func checkConfigPermissions(configPath string, cfg *AppConfig) error {
+	hasSecret := cfg.Gtimelog.AuthHeader != ""
+	for _, source := range cfg.Sources {
+		hasSecret = hasSecret || source.AuthHeader != ""
+	}
+	if !hasSecret {
+		return nil
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return err
+	}
+
+	perm := info.Mode().Perm()
+	if perm&0o044 == 0 {
+		return nil
+	}
+	readers := "its group"
+	if perm&0o004 != 0 {
+		readers = "everyone"
+	}
+	warning := fmt.Sprintf("%s holds an auth_header but is readable by %s, run chmod 600 on it or use auth_command", TimeConfigFile, readers)
+	slog.Warn(warning, "filePath", configPath, "mode", fmt.Sprintf("%04o", perm))
+	cfg.Warnings = append(cfg.Warnings, warning)
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretIsRedacted(t *testing.T) {
+	secret := Secret("Token ABCDXYZ")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("resolved", "authHeader", secret)
+
+	assert.NotContains(t, buf.String(), "ABCDXYZ")
+	assert.NotContains(t, fmt.Sprintf("%v %s %+v %#v", secret, secret, secret, secret), "ABCDXYZ")
+	assert.Equal(t, "Token ABCDXYZ", secret.Reveal())
+}
+
+func TestResolveAuthHeader(t *testing.T) {
+	dir := t.TempDir()
+	privateFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(privateFile, []byte("Token FROMFILE\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	sharedFile := filepath.Join(dir, "shared-token")
+	if err := os.WriteFile(sharedFile, []byte("Token SHARED\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	if err := os.Chmod(sharedFile, 0o644); err != nil {
+		t.Fatalf("Failed to chmod token file: %v", err)
+	}
+	t.Setenv("TTIMELOG_TEST_AUTH", "Token FROMENV")
+
+	tests := []struct {
+		name     string
+		setup    func(cfg *AppConfig)
+		expected string
+		wantErr  bool
+	}{
+		{"plain", func(cfg *AppConfig) { cfg.Gtimelog.AuthHeader = "Token PLAIN" }, "Token PLAIN", false},
+		{"env", func(cfg *AppConfig) {
+			cfg.Gtimelog.AuthHeader = "Token PLAIN"
+			cfg.Gtimelog.AuthHeaderEnv = "TTIMELOG_TEST_AUTH"
+		}, "Token FROMENV", false},
+		{"missing env", func(cfg *AppConfig) { cfg.Gtimelog.AuthHeaderEnv = "TTIMELOG_TEST_UNSET" }, "", true},
+		{"file", func(cfg *AppConfig) { cfg.Gtimelog.AuthHeaderFile = privateFile }, "Token FROMFILE", false},
+		{"readable file", func(cfg *AppConfig) { cfg.Gtimelog.AuthHeaderFile = sharedFile }, "", true},
+		{"command", func(cfg *AppConfig) { cfg.Gtimelog.AuthCommand = "echo 'Token FROMCMD'" }, "Token FROMCMD", false},
+		{"failing command", func(cfg *AppConfig) { cfg.Gtimelog.AuthCommand = "exit 3" }, "", true},
+		{"silent command", func(cfg *AppConfig) { cfg.Gtimelog.AuthCommand = "true" }, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg AppConfig
+			tt.setup(&cfg)
+			err := cfg.ResolveAuthHeader(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Gtimelog.AuthHeader.Reveal())
+		})
+	}
+}
+
+func TestLoadConfigWarnsAboutReadableSecrets(t *testing.T) {
+	testConfig := `
+[gtimelog]
+auth_header = Token ABCDXYZ
+`
+	for _, mode := range []os.FileMode{0o600, 0o640, 0o644} {
+		t.Run(fmt.Sprintf("%04o", mode), func(t *testing.T) {
+			tempDir := t.TempDir()
+			configPath := filepath.Join(tempDir, TimeConfigFile)
+			if err := os.WriteFile(configPath, []byte(testConfig), 0o600); err != nil {
+				t.Fatalf("Failed to write temp file: %v", err)
+			}
+			if err := os.Chmod(configPath, mode); err != nil {
+				t.Fatalf("Failed to chmod: %v", err)
+			}
+
+			appConfig, err := LoadConfig(tempDir)
+			assert.NoError(t, err)
+			if mode == 0o600 {
+				assert.Empty(t, appConfig.Warnings)
+			} else {
+				assert.Len(t, appConfig.Warnings, 1)
+			}
+		})
+	}
+}
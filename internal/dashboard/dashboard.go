@@ -0,0 +1,99 @@
+// Package dashboard serves a read-only web view of the timelog on localhost:
+// today's entries, the week's totals and the work per project, as HTML and
+// JSON, updated live with server-sent events.
+package dashboard
+
+import (
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// Today is served by /api/today
+type Today struct {
+	Date    string                  `json:"date"`
+	Totals  timelog.Totals          `json:"totals"`
+	Entries []timelog.ExportedEntry `json:"entries"`
+}
+
+// Day is a day of the week
+type Day struct {
+	Date    string `json:"date"`
+	Weekday string `json:"weekday"`
+	timelog.Totals
+}
+
+// Week is served by /api/week
+type Week struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Totals timelog.Totals `json:"totals"`
+	Days   []Day          `json:"days"`
+}
+
+// Project is the work on a project this week
+type Project struct {
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes"`
+	// Percent is the share of the week's work
+	Percent int `json:"percent"`
+}
+
+// Snapshot is everything the dashboard shows
+type Snapshot struct {
+	Generated time.Time `json:"generated"`
+	Today     Today     `json:"today"`
+	Week      Week      `json:"week"`
+	Projects  []Project `json:"projects"`
+}
+
+// NewSnapshot computes the dashboard of the week containing now
+func NewSnapshot(entries []timelog.Entry, now time.Time) Snapshot {
+	weekStart, weekEnd := timelog.WeekRange(now)
+	weekEntries := timelog.EntriesBetween(entries, weekStart, weekEnd)
+	days := timelog.SummarizeDays(weekEntries)
+
+	snapshot := Snapshot{
+		Generated: now,
+		Today: Today{
+			Date:    timelog.DateKey(now),
+			Entries: []timelog.ExportedEntry{},
+		},
+		Week: Week{
+			From: timelog.DateKey(weekStart),
+			// inclusive, like the dates shown
+			To:   timelog.DateKey(weekEnd.AddDate(0, 0, -1)),
+			Days: make([]Day, 0, 7),
+		},
+		Projects: []Project{},
+	}
+
+	var week timelog.Stats
+	for day := weekStart; day.Before(weekEnd); day = day.AddDate(0, 0, 1) {
+		stats := days[timelog.DateKey(day)]
+		week.Work += stats.Work
+		week.Slack += stats.Slack
+		snapshot.Week.Days = append(snapshot.Week.Days, Day{
+			Date:    timelog.DateKey(day),
+			Weekday: day.Weekday().String(),
+			Totals:  timelog.ExportTotals(stats),
+		})
+	}
+	snapshot.Week.Totals = timelog.ExportTotals(week)
+	snapshot.Today.Totals = timelog.ExportTotals(days[snapshot.Today.Date])
+
+	for _, entry := range weekEntries {
+		if timelog.DateKey(entry.EndTime) == snapshot.Today.Date {
+			snapshot.Today.Entries = append(snapshot.Today.Entries, timelog.ExportEntry(entry))
+		}
+	}
+
+	for _, total := range timelog.SummarizeBy(weekEntries, timelog.ProjectOf) {
+		project := Project{Name: total.Name, Minutes: int(total.Duration.Minutes())}
+		if week.Work > 0 {
+			project.Percent = int(100 * total.Duration / week.Work)
+		}
+		snapshot.Projects = append(snapshot.Projects, project)
+	}
+	return snapshot
+}
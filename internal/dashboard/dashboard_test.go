@@ -0,0 +1,138 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC)
+	entries := []timelog.Entry{
+		{EndTime: time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC), Description: "Acme: last week", Duration: time.Hour},
+		{EndTime: time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC), Description: "Acme: review", Duration: 3 * time.Hour},
+		{EndTime: time.Date(2025, 3, 12, 9, 0, 0, 0, time.UTC), Description: "arrived**"},
+		{EndTime: time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC), Description: "Initech: fix bug", Duration: time.Hour},
+		{EndTime: time.Date(2025, 3, 12, 10, 30, 0, 0, time.UTC), Description: "coffee **", Duration: 30 * time.Minute},
+	}
+
+	snapshot := NewSnapshot(entries, now)
+	assert.Equal(t, "2025-03-12", snapshot.Today.Date)
+	assert.Len(t, snapshot.Today.Entries, 3)
+	assert.Equal(t, timelog.Totals{Work: 60, Slack: 30}, snapshot.Today.Totals)
+
+	assert.Equal(t, "2025-03-10", snapshot.Week.From)
+	assert.Equal(t, "2025-03-16", snapshot.Week.To)
+	assert.Len(t, snapshot.Week.Days, 7)
+	assert.Equal(t, "Monday", snapshot.Week.Days[0].Weekday)
+	assert.Equal(t, 180, snapshot.Week.Days[0].Work)
+	assert.Equal(t, timelog.Totals{Work: 240, Slack: 30}, snapshot.Week.Totals)
+
+	assert.Equal(t, []Project{
+		{Name: "Acme", Minutes: 180, Percent: 75},
+		{Name: "Initech", Minutes: 60, Percent: 25},
+	}, snapshot.Projects)
+}
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ttimelog.txt")
+	content := "2025-03-12 09:00 +0000: arrived**\n2025-03-12 10:00 +0000: Acme: <b>fix</b>\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	server := New(path)
+	server.now = func() time.Time { return time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC) }
+	httpServer := httptest.NewUnstartedServer(nil)
+	_, port, _ := net.SplitHostPort(httpServer.Listener.Addr().String())
+	httpServer.Config.Handler = server.Handler(port)
+	httpServer.Start()
+	t.Cleanup(httpServer.Close)
+	return server, httpServer
+}
+
+func TestHandler(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	resp, err := http.Get(httpServer.URL + "/api/today")
+	if err != nil {
+		t.Fatalf("Failed to get today: %v", err)
+	}
+	defer resp.Body.Close()
+	var today Today
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&today))
+	assert.Len(t, today.Entries, 2)
+	assert.Equal(t, 60, today.Totals.Work)
+
+	resp, err = http.Get(httpServer.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to get page: %v", err)
+	}
+	defer resp.Body.Close()
+	var page strings.Builder
+	_, _ = bufio.NewReader(resp.Body).WriteTo(&page)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, page.String(), "&lt;b&gt;fix&lt;/b&gt;")
+	assert.Contains(t, page.String(), "1h0m")
+
+	resp, err = http.Get(httpServer.URL + "/nope")
+	if err != nil {
+		t.Fatalf("Failed to get unknown path: %v", err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHandlerChecksHost(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	_, port, _ := net.SplitHostPort(httpServer.Listener.Addr().String())
+
+	for host, status := range map[string]int{
+		"localhost:" + port:        http.StatusOK,
+		"127.0.0.1:" + port:        http.StatusOK,
+		"[::1]:" + port:            http.StatusOK,
+		"evil.example.com:" + port: http.StatusForbidden,
+		"localhost":                http.StatusForbidden,
+		"localhost:1":              http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/api/today", nil)
+		req.Host = host
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get today: %v", err)
+		}
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode, host)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server, httpServer := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to get events: %v", err)
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	server.Notify()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "event: changed\n", line)
+}
@@ -0,0 +1,185 @@
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+//go:embed dashboard.html
+var pageTemplate string
+
+var page = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"duration": func(minutes int) string {
+		return timelog.FormatStatDuration(time.Duration(minutes) * time.Minute)
+	},
+	"clock": func(t time.Time) string {
+		return t.Format("15:04")
+	},
+}).Parse(pageTemplate))
+
+// Server serves the dashboard of a timelog file
+type Server struct {
+	timeLogFilePath string
+	now             func() time.Time
+
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func New(timeLogFilePath string) *Server {
+	return &Server{
+		timeLogFilePath: timeLogFilePath,
+		now:             time.Now,
+		clients:         make(map[chan struct{}]struct{}),
+	}
+}
+
+// Handler serves the page at "/", its data at "/api/today", "/api/week"
+// and "/api/projects", and change events at "/events". Only requests for
+// localhost on port are answered, see checkHost.
+func (s *Server) Handler(port string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.servePage)
+	mux.HandleFunc("GET /api/today", s.serveJSON(func(snapshot Snapshot) any { return snapshot.Today }))
+	mux.HandleFunc("GET /api/week", s.serveJSON(func(snapshot Snapshot) any { return snapshot.Week }))
+	mux.HandleFunc("GET /api/projects", s.serveJSON(func(snapshot Snapshot) any { return snapshot.Projects }))
+	mux.HandleFunc("GET /events", s.serveEvents)
+	return checkHost(port, mux)
+}
+
+// checkHost rejects requests whose Host is not localhost on port. Listening
+// on loopback doesn't keep out other websites: DNS rebinding points their
+// name at 127.0.0.1, the browser then sends their Host header.
+func checkHost(port string, next http.Handler) http.Handler {
+	allowed := map[string]bool{
+		net.JoinHostPort("localhost", port): true,
+		net.JoinHostPort("127.0.0.1", port): true,
+		net.JoinHostPort("::1", port):       true,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed[strings.ToLower(r.Host)] {
+			slog.Warn("Rejected dashboard request", "host", r.Host, "path", r.URL.Path)
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve serves the dashboard on the listener until ctx is done
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Handler:           s.Handler(port),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		// event streams never finish on their own, don't wait for them
+		_ = server.Close()
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Notify tells the open pages that the timelog changed
+func (s *Server) Notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+			// a change is pending already
+		}
+	}
+}
+
+func (s *Server) snapshot() (Snapshot, error) {
+	entries, _, _, err := timelog.LoadEntries(s.timeLogFilePath)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to load [%s] with error[%v]", s.timeLogFilePath, err)
+	}
+	return NewSnapshot(entries, s.now()), nil
+}
+
+func (s *Server) servePage(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.snapshot()
+	if err != nil {
+		slog.Error("Failed to serve dashboard", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, snapshot); err != nil {
+		slog.Error("Failed to render dashboard", "error", err)
+	}
+}
+
+func (s *Server) serveJSON(part func(Snapshot) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot, err := s.snapshot()
+		if err != nil {
+			slog.Error("Failed to serve dashboard data", "path", r.URL.Path, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(part(snapshot)); err != nil {
+			slog.Error("Failed to write dashboard data", "error", err)
+		}
+	}
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	changes := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[changes] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, changes)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-changes:
+			if _, err := fmt.Fprint(w, "event: changed\ndata: {}\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package editor builds the command to open a file in the user's editor
+package editor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const fallbackEditor = "vi"
+
+// Name returns the configured editor command line, $VISUAL takes precedence
+// over $EDITOR as the TUI is suspended while editing.
+func Name() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return fallbackEditor
+}
+
+// Args returns the arguments to open filePath at line (1-based) with the
+// given editor command line. Line 0 opens the file at its end.
+func Args(editor, filePath string, line int) ([]string, error) {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return nil, errors.New("no editor configured, set $VISUAL or $EDITOR")
+	}
+
+	name := filepath.Base(fields[0])
+	switch name {
+	case "code", "code-insiders", "codium":
+		// VS Code returns immediately unless told to wait
+		args := append(fields, "--wait")
+		if line > 0 {
+			return append(args, "--goto", filePath+":"+strconv.Itoa(line)), nil
+		}
+		return append(args, filePath), nil
+	case "subl", "hx", "helix", "zed":
+		if line > 0 {
+			return append(fields, filePath+":"+strconv.Itoa(line)), nil
+		}
+		return append(fields, filePath), nil
+	}
+
+	// vi, vim, nvim, nano, emacs, micro, kak and most others understand +N,
+	// a lone "+" jumps to the last line in vi and nano
+	position := "+"
+	if line > 0 {
+		position += strconv.Itoa(line)
+	}
+	return append(fields, position, filePath), nil
+}
+
+// Command returns the command opening filePath at line in the user's editor
+func Command(filePath string, line int) (*exec.Cmd, error) {
+	args, err := Args(Name(), filePath, line)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
@@ -0,0 +1,43 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"vim", 12, []string{"vim", "+12", "log.txt"}},
+		{"/usr/bin/nvim -u NONE", 3, []string{"/usr/bin/nvim", "-u", "NONE", "+3", "log.txt"}},
+		{"nano", 0, []string{"nano", "+", "log.txt"}},
+		{"code", 7, []string{"code", "--wait", "--goto", "log.txt:7"}},
+		{"hx", 7, []string{"hx", "log.txt:7"}},
+	}
+
+	for _, test := range tests {
+		args, err := Args(test.editor, "log.txt", test.line)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, args, test.editor)
+	}
+
+	_, err := Args("  ", "log.txt", 1)
+	assert.Error(t, err)
+}
+
+func TestName(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "nano")
+	assert.Equal(t, "nano", Name())
+
+	t.Setenv("VISUAL", "nvim")
+	assert.Equal(t, "nvim", Name())
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	assert.Equal(t, "vi", Name())
+}
@@ -0,0 +1,141 @@
+// Package githook turns git commits into timelog entries: it installs a
+// post-commit hook calling ttimelog and reads the commit it was called for.
+package githook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// hookMarker identifies hooks installed by ttimelog
+const hookMarker = "# installed by ttimelog"
+
+// ErrHookExists is returned by Install when another post-commit hook is in
+// the way
+var ErrHookExists = errors.New("a post-commit hook exists already")
+
+// Commit is the commit a hook was called for
+type Commit struct {
+	// Repo is the name of the repository's top-level directory
+	Repo    string
+	Branch  string
+	Subject string
+	// Replayed is set for commits a rebase or cherry-pick re-creates on a
+	// detached HEAD, they were made already
+	Replayed bool
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed with error[%v]: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ReadCommit reads HEAD of the repository containing dir and tells whether
+// a rebase or cherry-pick is replaying it
+func ReadCommit(ctx context.Context, dir string) (Commit, error) {
+	toplevel, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return Commit{}, err
+	}
+	subject, err := git(ctx, dir, "log", "-1", "--format=%s")
+	if err != nil {
+		return Commit{}, err
+	}
+	// "HEAD" when detached, e.g. during a rebase
+	branch, err := git(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return Commit{}, err
+	}
+	replayed := branch == "HEAD"
+	if !replayed {
+		if replayed, err = rebasing(ctx, dir); err != nil {
+			return Commit{}, err
+		}
+	}
+	return Commit{Repo: filepath.Base(toplevel), Branch: branch, Subject: subject, Replayed: replayed}, nil
+}
+
+// rebasing tells whether a rebase is in progress in the repository
+// containing dir
+func rebasing(ctx context.Context, dir string) (bool, error) {
+	for _, state := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := git(ctx, dir, "rev-parse", "--git-path", state)
+		if err != nil {
+			return false, err
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Description is the timelog description of the commit. The project is
+// looked up by repository name, unmapped repositories use their name. A
+// topic branch is added after the subject, it often names the ticket.
+func (c Commit) Description(projects map[string]string) string {
+	project := c.Repo
+	if mapped, ok := projects[c.Repo]; ok && strings.TrimSpace(mapped) != "" {
+		project = strings.TrimSpace(mapped)
+	}
+	description := project + ": " + c.Subject
+	switch c.Branch {
+	case "", "HEAD", "main", "master":
+	default:
+		description += " (" + c.Branch + ")"
+	}
+	return description
+}
+
+// Install writes a post-commit hook running command into the repository
+// containing dir and returns its path. An existing hook is only replaced
+// when it was installed by ttimelog or with force.
+func Install(ctx context.Context, dir, command string, force bool) (string, error) {
+	hooksDir, err := git(ctx, dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(hooksDir, "post-commit")
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", err
+	case !force && !bytes.Contains(existing, []byte(hookMarker)):
+		return path, ErrHookExists
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s\n# a failure must not get in the way of committing\n%s || true\n", hookMarker, command)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write hook[%s] with error[%v]", path, err)
+	}
+	return path, nil
+}
+
+// HookCommand is the line calling executable from a hook, quoted for sh
+func HookCommand(executable string) string {
+	return "'" + strings.ReplaceAll(executable, "'", `'\''`) + "' git-hook post-commit"
+}
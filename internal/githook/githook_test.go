@@ -0,0 +1,124 @@
+package githook
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "webshop")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create repo dir: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Fix login redirect"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestReadCommit(t *testing.T) {
+	dir := newRepo(t)
+
+	commit, err := ReadCommit(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Commit{Repo: "webshop", Branch: "main", Subject: "Fix login redirect"}, commit)
+	assert.False(t, commit.Replayed)
+
+	assert.Equal(t, "Acme:Web:Dev: Fix login redirect", commit.Description(map[string]string{"webshop": "Acme:Web:Dev"}))
+	assert.Equal(t, "webshop: Fix login redirect", commit.Description(nil))
+
+	commit.Branch = "ABC-123-login"
+	assert.Equal(t, "webshop: Fix login redirect (ABC-123-login)", commit.Description(nil))
+}
+
+func TestReadCommitReplayed(t *testing.T) {
+	dir := newRepo(t)
+	ctx := context.Background()
+
+	// a rebase in progress on a branch
+	rebaseDir := filepath.Join(dir, ".git", "rebase-merge")
+	if err := os.Mkdir(rebaseDir, 0o755); err != nil {
+		t.Fatalf("Failed to create rebase dir: %v", err)
+	}
+	commit, err := ReadCommit(ctx, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "main", commit.Branch)
+	assert.True(t, commit.Replayed)
+	if err := os.Remove(rebaseDir); err != nil {
+		t.Fatalf("Failed to remove rebase dir: %v", err)
+	}
+
+	// a detached HEAD, like a rebase or cherry-pick onto a commit
+	cmd := exec.Command("git", "checkout", "-q", "--detach")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git checkout failed: %v: %s", err, out)
+	}
+	commit, err = ReadCommit(ctx, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "HEAD", commit.Branch)
+	assert.True(t, commit.Replayed)
+}
+
+func TestInstall(t *testing.T) {
+	dir := newRepo(t)
+	ctx := context.Background()
+	command := HookCommand("/opt/it's here/ttimelog")
+	assert.Equal(t, `'/opt/it'\''s here/ttimelog' git-hook post-commit`, command)
+
+	path, err := Install(ctx, dir, command, false)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git", "hooks", "post-commit"), path)
+	content, _ := os.ReadFile(path)
+	assert.Contains(t, string(content), command)
+	info, _ := os.Stat(path)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// installing again replaces our own hook
+	_, err = Install(ctx, dir, command, false)
+	assert.NoError(t, err)
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	_, err = Install(ctx, dir, command, false)
+	assert.ErrorIs(t, err, ErrHookExists)
+	_, err = Install(ctx, dir, command, true)
+	assert.NoError(t, err)
+}
+
+func TestSuggestions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suggestions.txt")
+
+	suggestions, err := LoadSuggestions(path)
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	assert.NoError(t, AddSuggestion(path, "Acme: fix login"))
+	assert.NoError(t, AddSuggestion(path, "Acme: add\ntests"))
+	assert.NoError(t, AddSuggestion(path, "Acme: fix login"))
+	suggestions, err = LoadSuggestions(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Acme: fix login", "Acme: add tests"}, suggestions)
+
+	assert.NoError(t, RemoveSuggestion(path, "Acme: fix login"))
+	assert.NoError(t, RemoveSuggestion(path, "Acme: add tests"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
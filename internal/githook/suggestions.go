@@ -0,0 +1,66 @@
+package githook
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// LoadSuggestions reads the suggested descriptions, oldest first. A missing
+// file has none.
+func LoadSuggestions(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var suggestions []string
+	for line := range strings.SplitSeq(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			suggestions = append(suggestions, line)
+		}
+	}
+	return suggestions, nil
+}
+
+// AddSuggestion queues description, unless it's queued already
+func AddSuggestion(path, description string) error {
+	// one line per suggestion
+	description = strings.Join(strings.Fields(description), " ")
+	suggestions, err := LoadSuggestions(path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(suggestions, description) {
+		return nil
+	}
+	return save(path, append(suggestions, description))
+}
+
+// RemoveSuggestion drops description, once it was used or dismissed
+func RemoveSuggestion(path, description string) error {
+	suggestions, err := LoadSuggestions(path)
+	if err != nil {
+		return err
+	}
+	index := slices.Index(suggestions, description)
+	if index < 0 {
+		return nil
+	}
+	return save(path, slices.Delete(suggestions, index, index+1))
+}
+
+func save(path string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return timelog.WriteFileAtomic(path, []byte(strings.Join(suggestions, "\n")+"\n"))
+}
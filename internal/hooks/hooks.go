@@ -0,0 +1,207 @@
+// Package hooks runs user commands on timelog events. The event is passed
+// as JSON on stdin and its type in $TTIMELOG_EVENT.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+const (
+	EntryAdded        = "entry_added"
+	Arrived           = "arrived"
+	TargetReached     = "target_reached"
+	ProjectsRefreshed = "projects_refreshed"
+)
+
+// DefaultTimeout bounds a hook when no timeout is configured
+const DefaultTimeout = 10 * time.Second
+
+// maxOutput is how much of a hook's output is logged
+const maxOutput = 4096
+
+// Event is what happened, written to the hooks' stdin
+type Event struct {
+	Type string    `json:"type"`
+	At   time.Time `json:"at"`
+	// Entry is the logged entry of entry_added and arrived
+	Entry *timelog.ExportedEntry `json:"entry,omitempty"`
+	// Work and Target are today's minutes, set for target_reached
+	Work   int `json:"work,omitempty"`
+	Target int `json:"target,omitempty"`
+	// Projects is the number of projects, set for projects_refreshed
+	Projects int `json:"projects,omitempty"`
+}
+
+// Runner runs the hooks of events in the background
+type Runner struct {
+	dir      string
+	commands map[string]string
+	timeout  time.Duration
+	wg       sync.WaitGroup
+}
+
+// FromConfig runs the executables in the hooks directory and the commands
+// of the [hooks] section
+func FromConfig(appConfig *config.AppConfig) *Runner {
+	hooks := appConfig.Hooks
+	timeout := hooks.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Runner{
+		dir: filepath.Join(appConfig.TimeLogDirPath, config.HooksDirname),
+		commands: map[string]string{
+			EntryAdded:        hooks.EntryAdded,
+			Arrived:           hooks.Arrived,
+			TargetReached:     hooks.TargetReached,
+			ProjectsRefreshed: hooks.ProjectsRefreshed,
+		},
+		timeout: timeout,
+	}
+}
+
+// hook is a command to run for an event
+type hook struct {
+	name string
+	args []string
+}
+
+func (r *Runner) hooks(eventType string) []hook {
+	var hooks []hook
+	path := filepath.Join(r.dir, eventType)
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0 {
+			hooks = append(hooks, hook{name: path, args: []string{path}})
+		} else {
+			slog.Warn("Skipping hook that is not executable", "path", path)
+		}
+	}
+	if command := strings.TrimSpace(r.commands[eventType]); command != "" {
+		hooks = append(hooks, hook{name: "[hooks] " + eventType, args: []string{"sh", "-c", command}})
+	}
+	return hooks
+}
+
+// Fire runs the hooks of the events without waiting for them, one after the
+// other in the order of the events
+func (r *Runner) Fire(events ...Event) {
+	type run struct {
+		hook      hook
+		eventType string
+		input     []byte
+	}
+	var runs []run
+	for _, event := range events {
+		hooks := r.hooks(event.Type)
+		if len(hooks) == 0 {
+			continue
+		}
+		input, err := json.Marshal(event)
+		if err != nil {
+			slog.Error("Failed to encode hook event", "event", event.Type, "error", err)
+			continue
+		}
+		for _, hook := range hooks {
+			runs = append(runs, run{hook: hook, eventType: event.Type, input: input})
+		}
+	}
+	if len(runs) == 0 {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for _, run := range runs {
+			if err := r.run(run.hook, run.eventType, run.input); err != nil {
+				slog.Error("Hook failed", "hook", run.hook.name, "event", run.eventType, "error", err)
+			}
+		}
+	}()
+}
+
+// EntryLogged fires the events of logging entry after entries, in this
+// order: entry_added, arrived when it starts the day and target_reached when
+// it completes the day's target
+func (r *Runner) EntryLogged(entries []timelog.Entry, entry timelog.Entry, startsDay bool, target time.Duration) {
+	exported := timelog.ExportEntry(entry)
+	events := []Event{{Type: EntryAdded, At: entry.EndTime, Entry: &exported}}
+	if startsDay {
+		events = append(events, Event{Type: Arrived, At: entry.EndTime, Entry: &exported})
+	}
+
+	var day []timelog.Entry
+	for _, earlier := range entries {
+		if timelog.DateKey(earlier.EndTime) == timelog.DateKey(entry.EndTime) {
+			day = append(day, earlier)
+		}
+	}
+	before := timelog.NewDayStatus(day, entry.EndTime, target)
+	after := timelog.NewDayStatus(append(day, entry), entry.EndTime, target)
+	if target > 0 && before.Work < target && after.Work >= target {
+		events = append(events, Event{
+			Type:   TargetReached,
+			At:     entry.EndTime,
+			Work:   int(after.Work.Minutes()),
+			Target: int(target.Minutes()),
+		})
+	}
+	r.Fire(events...)
+}
+
+// ProjectsRefreshed fires projects_refreshed for a loaded list of count
+// projects
+func (r *Runner) ProjectsRefreshed(count int) {
+	r.Fire(Event{Type: ProjectsRefreshed, At: time.Now(), Projects: count})
+}
+
+// Wait waits for the fired hooks, e.g. before a command exits
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) run(hook hook, eventType string, input []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, hook.args[0], hook.args[1:]...)
+	if _, err := os.Stat(r.dir); err == nil {
+		cmd.Dir = r.dir
+	}
+	cmd.Env = append(os.Environ(), "TTIMELOG_EVENT="+eventType)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// children keeping the output open don't hold up the timeout
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	out := strings.TrimSpace(output.String())
+	if len(out) > maxOutput {
+		out = out[:maxOutput] + "…"
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s, output: %s", r.timeout, out)
+	}
+	if err != nil {
+		return fmt.Errorf("%w, output: %s", err, out)
+	}
+	slog.Info("Hook ran", "hook", hook.name, "event", eventType, "took", time.Since(start), "output", out)
+	return nil
+}
@@ -0,0 +1,127 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRunner returns a runner whose hooks append the events they get to
+// events.log in the returned directory
+func newRunner(t *testing.T) (*Runner, string) {
+	t.Helper()
+	dir := t.TempDir()
+	hooksDir := filepath.Join(dir, config.HooksDirname)
+	if err := os.Mkdir(hooksDir, 0o755); err != nil {
+		t.Fatalf("Failed to create hooks dir: %v", err)
+	}
+	script := "#!/bin/sh\n(cat; echo) >> ../events.log\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, EntryAdded), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+
+	appConfig := &config.AppConfig{TimeLogDirPath: dir}
+	appConfig.Hooks.TargetReached = `echo "$TTIMELOG_EVENT" >> ../events.log`
+	return FromConfig(appConfig), dir
+}
+
+func readEvents(t *testing.T, dir string) []string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, "events.log"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestEntryLogged(t *testing.T) {
+	runner, dir := newRunner(t)
+	arrived := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	entries := []timelog.Entry{
+		timelog.NewEntry(arrived.AddDate(0, 0, -1).Add(7*time.Hour), "Acme: yesterday", 7*time.Hour),
+		timelog.NewEntry(arrived, "arrived", 0),
+		timelog.NewEntry(arrived.Add(4*time.Hour), "Acme: Web: fix login", 4*time.Hour),
+	}
+
+	entry := timelog.NewEntry(arrived.Add(7*time.Hour), "Acme: Web: tests", 3*time.Hour)
+	runner.EntryLogged(entries, entry, false, 8*time.Hour)
+	runner.Wait()
+	events := readEvents(t, dir)
+	assert.Len(t, events, 1)
+	var event Event
+	assert.NoError(t, json.Unmarshal([]byte(events[0]), &event))
+	assert.Equal(t, EntryAdded, event.Type)
+	assert.Equal(t, "Acme", event.Entry.Project)
+	assert.Equal(t, "Web: tests", event.Entry.Task)
+	assert.Equal(t, 180, event.Entry.Minutes)
+
+	// crossing the target, yesterday doesn't count
+	entries = append(entries, entry)
+	entry = timelog.NewEntry(arrived.Add(8*time.Hour), "Acme: Web: review", time.Hour)
+	runner.EntryLogged(entries, entry, false, 8*time.Hour)
+	runner.Wait()
+	events = readEvents(t, dir)
+	assert.Len(t, events, 3)
+	assert.Contains(t, events, TargetReached)
+
+	// past the target it's not reached again
+	entries = append(entries, entry)
+	entry = timelog.NewEntry(arrived.Add(9*time.Hour), "Acme: Web: deploy", time.Hour)
+	runner.EntryLogged(entries, entry, false, 8*time.Hour)
+	runner.Wait()
+	assert.Len(t, readEvents(t, dir), 4)
+}
+
+func TestEntryLoggedOrder(t *testing.T) {
+	runner, dir := newRunner(t)
+	runner.commands[Arrived] = `echo "$TTIMELOG_EVENT" >> ../events.log`
+	// run in parallel, arrived would finish before the slower entry_added
+	script := "#!/bin/sh\nsleep 0.2\n(cat; echo) >> ../events.log\n"
+	if err := os.WriteFile(filepath.Join(dir, config.HooksDirname, EntryAdded), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+
+	arrived := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	runner.EntryLogged(nil, timelog.NewEntry(arrived, "arrived**", 0), true, 8*time.Hour)
+	runner.Wait()
+
+	events := readEvents(t, dir)
+	assert.Len(t, events, 2)
+	var event Event
+	assert.NoError(t, json.Unmarshal([]byte(events[0]), &event))
+	assert.Equal(t, EntryAdded, event.Type)
+	assert.Equal(t, Arrived, events[1])
+}
+
+func TestFireSkipsNonExecutable(t *testing.T) {
+	runner, dir := newRunner(t)
+	if err := os.Chmod(filepath.Join(dir, config.HooksDirname, EntryAdded), 0o644); err != nil {
+		t.Fatalf("Failed to chmod hook: %v", err)
+	}
+	runner.Fire(Event{Type: EntryAdded, At: time.Now()})
+	runner.Fire(Event{Type: Arrived, At: time.Now()})
+	runner.Wait()
+	assert.Empty(t, readEvents(t, dir))
+}
+
+func TestFireTimeout(t *testing.T) {
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	appConfig.Hooks.Timeout = 100 * time.Millisecond
+	appConfig.Hooks.Arrived = "sleep 5"
+	runner := FromConfig(appConfig)
+
+	start := time.Now()
+	runner.Fire(Event{Type: Arrived, At: start})
+	runner.Wait()
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
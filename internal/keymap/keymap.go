@@ -0,0 +1,413 @@
+// Package keymap defines the remappable keybindings of the TUI
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type Action string
+
+const (
+	Quit          Action = "quit"
+	Help          Action = "help"
+	Submit        Action = "submit"
+	RetrySave     Action = "retry_save"
+	EditEntry     Action = "edit_entry"
+	AmendEntry    Action = "amend_entry"
+	DeleteEntry   Action = "delete_entry"
+	Undo          Action = "undo"
+	Redo          Action = "redo"
+	ToggleFocus   Action = "toggle_focus"
+	OpenProjects  Action = "open_projects"
+	OpenHeatmap   Action = "open_heatmap"
+	OpenCharts    Action = "open_charts"
+	OpenTimesheet Action = "open_timesheet"
+	FocusHeader   Action = "focus_header"
+	FocusStats    Action = "focus_stats"
+	FocusTable    Action = "focus_table"
+	FocusFooter   Action = "focus_footer"
+	Up            Action = "up"
+	Down          Action = "down"
+	Left          Action = "left"
+	Right         Action = "right"
+	Toggle        Action = "toggle"
+	Refresh       Action = "refresh"
+	Select        Action = "select"
+	Close         Action = "close"
+	Today         Action = "today"
+	SwitchChart   Action = "switch_chart"
+	Day           Action = "day"
+	Week          Action = "week"
+	Month         Action = "month"
+	Category      Action = "category"
+	CheckProjects Action = "check_projects"
+	RemapProject  Action = "remap_project"
+	RemapFromFile Action = "remap_from_file"
+	All           Action = "all"
+	Dates         Action = "dates"
+
+	// suggested entries, see the git-hook command
+	UseSuggestion  Action = "use_suggestion"
+	DropSuggestion Action = "drop_suggestion"
+
+	OpenStandup Action = "open_standup"
+	Copy        Action = "copy"
+)
+
+// Context is the part of the UI that has focus, each context has its own
+// set of active bindings.
+type Context string
+
+const (
+	ContextMain      Context = "main"
+	ContextProjects  Context = "projects"
+	ContextHeatmap   Context = "heatmap"
+	ContextCharts    Context = "charts"
+	ContextTimesheet Context = "timesheet"
+	ContextRemap     Context = "remap"
+	ContextHelp      Context = "help"
+	ContextStandup   Context = "standup"
+)
+
+const (
+	PresetDefault = "default"
+	PresetVim     = "vim"
+	PresetEmacs   = "emacs"
+)
+
+type usage struct {
+	action Action
+	desc   string
+}
+
+// contexts lists the actions available in every context, in help order
+var contexts = map[Context][]usage{
+	ContextMain: {
+		{Submit, "log task"},
+		{ToggleFocus, "toggle focus between task list and input"},
+		{RetrySave, "retry saving unsaved entries"},
+		{EditEntry, "edit selected entry in $EDITOR"},
+		{AmendEntry, "change description of selected entry"},
+		{DeleteEntry, "delete selected entry"},
+		{Undo, "undo last change"},
+		{Redo, "redo last undone change"},
+		{OpenProjects, "open project list"},
+		{OpenHeatmap, "open calendar heatmap"},
+		{OpenCharts, "open charts"},
+		{OpenTimesheet, "open timesheet submission"},
+		{RemapProject, "rename project of selected entry across history"},
+		{RemapFromFile, "rename projects listed in remap.txt"},
+		{UseSuggestion, "put suggested entry into input"},
+		{DropSuggestion, "dismiss suggested entry"},
+		{OpenStandup, "open standup summary"},
+		{FocusHeader, "focus header"},
+		{FocusStats, "focus stats"},
+		{FocusTable, "focus task list"},
+		{FocusFooter, "focus input"},
+		{Up, "previous task"},
+		{Down, "next task"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextProjects: {
+		{Up, "move up"},
+		{Down, "move down"},
+		{Toggle, "expand/collapse"},
+		{Select, "use project"},
+		{Refresh, "fetch project list again"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextHeatmap: {
+		{Left, "previous week"},
+		{Right, "next week"},
+		{Up, "previous day"},
+		{Down, "next day"},
+		{Today, "jump to today"},
+		{Select, "show entries of day"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextCharts: {
+		{SwitchChart, "days/projects"},
+		{Week, "week"},
+		{Month, "month"},
+		{Left, "previous period"},
+		{Right, "next period"},
+		{Category, "group by category"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextTimesheet: {
+		{Day, "day"},
+		{Week, "week"},
+		{Left, "previous period"},
+		{Right, "next period"},
+		{Up, "scroll up"},
+		{Down, "scroll down"},
+		{Select, "submit"},
+		{DeleteEntry, "drop rejected submissions"},
+		{CheckProjects, "list entries with unknown projects"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextRemap: {
+		{Week, "week"},
+		{Month, "month"},
+		{All, "whole history"},
+		{Dates, "from/to dates"},
+		{Left, "previous period"},
+		{Right, "next period"},
+		{Up, "scroll up"},
+		{Down, "scroll down"},
+		{Select, "rewrite timelog"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextStandup: {
+		{Up, "scroll up"},
+		{Down, "scroll down"},
+		{Copy, "copy to clipboard"},
+		{Close, "close"},
+		{Help, "show keybindings"},
+		{Quit, "quit"},
+	},
+	ContextHelp: {
+		{Close, "close"},
+		{Help, "close"},
+		{Quit, "quit"},
+	},
+}
+
+var defaults = map[Action][]string{
+	Quit:          {"ctrl+c"},
+	Help:          {"?", "f1"},
+	Submit:        {"enter"},
+	RetrySave:     {"ctrl+r"},
+	EditEntry:     {"e"},
+	AmendEntry:    {"r"},
+	DeleteEntry:   {"x", "delete"},
+	Undo:          {"ctrl+z"},
+	Redo:          {"alt+z"},
+	ToggleFocus:   {"esc"},
+	OpenProjects:  {"ctrl+p"},
+	OpenHeatmap:   {"ctrl+y"},
+	OpenCharts:    {"ctrl+g"},
+	OpenTimesheet: {"ctrl+t"},
+	FocusHeader:   {"1"},
+	FocusStats:    {"2"},
+	FocusTable:    {"3"},
+	FocusFooter:   {"4"},
+	Up:            {"k", "up"},
+	Down:          {"j", "down"},
+	Left:          {"h", "left"},
+	Right:         {"l", "right"},
+	Toggle:        {" "},
+	Refresh:       {"r"},
+	Select:        {"enter"},
+	Close:         {"esc"},
+	Today:         {"t"},
+	SwitchChart:   {"tab"},
+	Day:           {"d"},
+	Week:          {"w"},
+	Month:         {"m"},
+	Category:      {"c"},
+	CheckProjects: {"v"},
+	RemapProject:  {"M"},
+	RemapFromFile: {"alt+m"},
+	All:           {"a"},
+	Dates:         {"d"},
+
+	UseSuggestion:  {"tab"},
+	DropSuggestion: {"ctrl+x"},
+
+	OpenStandup: {"alt+s"},
+	Copy:        {"c", "y"},
+}
+
+// presets only list the actions that differ from the defaults
+var presets = map[string]map[Action][]string{
+	PresetDefault: {},
+	PresetVim: {
+		Close: {"esc", "q"},
+		Today: {"t", "0"},
+		Undo:  {"ctrl+z", "u"},
+		Redo:  {"alt+z", "ctrl+r"},
+		// ctrl+r is redo in vim
+		RetrySave: {"alt+r"},
+	},
+	PresetEmacs: {
+		OpenProjects:  {"alt+p"},
+		OpenHeatmap:   {"alt+y"},
+		OpenCharts:    {"alt+g"},
+		OpenTimesheet: {"alt+t"},
+		FocusHeader:   {"alt+1"},
+		FocusStats:    {"alt+2"},
+		FocusTable:    {"alt+3"},
+		FocusFooter:   {"alt+4"},
+		Up:            {"ctrl+p", "up"},
+		Down:          {"ctrl+n", "down"},
+		Left:          {"ctrl+b", "left"},
+		Right:         {"ctrl+f", "right"},
+		Close:         {"esc", "ctrl+g"},
+		Help:          {"f1", "ctrl+_"},
+	},
+}
+
+type KeyMap struct {
+	bindings map[Action]key.Binding
+}
+
+// Default returns the default preset without any overrides
+func Default() KeyMap {
+	keyMap, _ := New(PresetDefault, nil)
+	return keyMap
+}
+
+// FromConfig builds the keymap from the [keys] section of ttimelogrc
+func FromConfig(appConfig *config.AppConfig) (KeyMap, error) {
+	overrides := make(map[string]string, len(appConfig.Keys))
+	preset := PresetDefault
+	for name, value := range appConfig.Keys {
+		if name == "preset" {
+			preset = strings.TrimSpace(value)
+			continue
+		}
+		overrides[name] = value
+	}
+	return New(preset, overrides)
+}
+
+// New builds a keymap from a preset and per action overrides. Override
+// values are comma separated keys such as "ctrl+p, alt+p". It fails if the
+// same key ends up bound to two actions of one context.
+func New(preset string, overrides map[string]string) (KeyMap, error) {
+	presetKeys, ok := presets[preset]
+	if !ok {
+		return KeyMap{}, fmt.Errorf("unknown keys preset[%s], available presets are %s, %s and %s", preset, PresetDefault, PresetVim, PresetEmacs)
+	}
+
+	keys := make(map[Action][]string, len(defaults))
+	for action, defaultKeys := range defaults {
+		keys[action] = defaultKeys
+		if k, ok := presetKeys[action]; ok {
+			keys[action] = k
+		}
+	}
+
+	for name, value := range overrides {
+		action := Action(name)
+		if _, ok := defaults[action]; !ok {
+			return KeyMap{}, fmt.Errorf("unknown action[%s] in [keys]", name)
+		}
+		parsed := parseKeys(value)
+		if len(parsed) == 0 {
+			return KeyMap{}, fmt.Errorf("no keys given for action[%s]", name)
+		}
+		keys[action] = parsed
+	}
+
+	if err := checkConflicts(keys); err != nil {
+		return KeyMap{}, err
+	}
+
+	bindings := make(map[Action]key.Binding, len(keys))
+	for action, k := range keys {
+		bindings[action] = key.NewBinding(key.WithKeys(k...), key.WithHelp(displayKeys(k), string(action)))
+	}
+	return KeyMap{bindings: bindings}, nil
+}
+
+func parseKeys(value string) []string {
+	parsed := make([]string, 0)
+	for _, k := range strings.Split(value, ",") {
+		k = strings.TrimSpace(k)
+		switch k {
+		case "":
+			continue
+		case "space":
+			k = " "
+		}
+		parsed = append(parsed, k)
+	}
+	return parsed
+}
+
+func displayKeys(keys []string) string {
+	display := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		display[i] = k
+	}
+	return strings.Join(display, "/")
+}
+
+func checkConflicts(keys map[Action][]string) error {
+	// iterate in a fixed order so the reported conflict is stable
+	contextNames := make([]Context, 0, len(contexts))
+	for ctx := range contexts {
+		contextNames = append(contextNames, ctx)
+	}
+	slices.Sort(contextNames)
+
+	for _, ctx := range contextNames {
+		owners := make(map[string]Action)
+		for _, u := range contexts[ctx] {
+			for _, k := range keys[u.action] {
+				if owner, ok := owners[k]; ok && owner != u.action {
+					return fmt.Errorf("key[%s] is bound to both [%s] and [%s] in the %s view", displayKeys([]string{k}), owner, u.action, ctx)
+				}
+				owners[k] = u.action
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether msg triggers the action
+func (k KeyMap) Matches(msg fmt.Stringer, action Action) bool {
+	return key.Matches(msg, k.bindings[action])
+}
+
+// Binding returns the binding of an action, e.g. to reuse it in bubbles components
+func (k KeyMap) Binding(action Action) key.Binding {
+	return k.bindings[action]
+}
+
+// HelpEntry is a line of the help overlay
+type HelpEntry struct {
+	Keys string
+	Desc string
+}
+
+// Help returns the active bindings of a context in display order
+func (k KeyMap) Help(ctx Context) []HelpEntry {
+	entries := make([]HelpEntry, 0, len(contexts[ctx]))
+	for _, u := range contexts[ctx] {
+		entries = append(entries, HelpEntry{Keys: k.bindings[u.action].Help().Key, Desc: u.desc})
+	}
+	return entries
+}
+
+// ShortHelp renders the given actions of a context on a single line
+func (k KeyMap) ShortHelp(ctx Context, actions ...Action) string {
+	parts := make([]string, 0, len(actions))
+	for _, u := range contexts[ctx] {
+		if slices.Contains(actions, u.action) {
+			parts = append(parts, k.bindings[u.action].Help().Key+": "+u.desc)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,55 @@
+package keymap
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	keyMap, err := New(PresetDefault, map[string]string{
+		"open_projects": "ctrl+o, alt+p",
+		"toggle":        "space",
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, keyMap.Matches(tea.KeyMsg{Type: tea.KeyCtrlO}, OpenProjects))
+	assert.True(t, keyMap.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}, Alt: true}, OpenProjects))
+	assert.False(t, keyMap.Matches(tea.KeyMsg{Type: tea.KeyCtrlP}, OpenProjects))
+	assert.True(t, keyMap.Matches(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, Toggle))
+}
+
+func TestPresets(t *testing.T) {
+	for _, preset := range []string{PresetDefault, PresetVim, PresetEmacs} {
+		_, err := New(preset, nil)
+		assert.NoError(t, err, preset)
+	}
+
+	emacs, err := New(PresetEmacs, nil)
+	assert.NoError(t, err)
+	assert.True(t, emacs.Matches(tea.KeyMsg{Type: tea.KeyCtrlN}, Down))
+
+	_, err = New("nano", nil)
+	assert.Error(t, err)
+}
+
+func TestConflicts(t *testing.T) {
+	// ctrl+p already opens the project list
+	_, err := New(PresetDefault, map[string]string{"open_heatmap": "ctrl+p"})
+	assert.ErrorContains(t, err, "ctrl+p")
+
+	// same key in different contexts is fine
+	_, err = New(PresetDefault, map[string]string{"today": "1"})
+	assert.NoError(t, err)
+
+	_, err = New(PresetDefault, map[string]string{"fly": "f"})
+	assert.Error(t, err)
+}
+
+func TestHelp(t *testing.T) {
+	keyMap := Default()
+	help := keyMap.Help(ContextProjects)
+	assert.Equal(t, HelpEntry{Keys: "k/up", Desc: "move up"}, help[0])
+	assert.Equal(t, HelpEntry{Keys: "space", Desc: "expand/collapse"}, help[2])
+}
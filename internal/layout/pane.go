@@ -12,45 +12,51 @@ type Pane struct {
 	Focused bool
 	Width   int
 	Height  int
+	Styles  Styles
 }
 
-const (
-	paneBorderBlurColor  = lipgloss.Color("#3b4261")
-	paneBorderFocusColor = lipgloss.Color("#7aa2f7")
-)
-
-var (
-	paneBorderBlur = lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), false, true, true, true).
-			BorderForeground(paneBorderBlurColor)
+// Styles are the borders of a pane, their colours are those of the top
+// border
+type Styles struct {
+	Blurred lipgloss.Style
+	Focused lipgloss.Style
+}
 
-	paneBorderFocus = lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), false, true, true, true).
-			BorderForeground(paneBorderFocusColor)
-)
+// DefaultStyles are rounded borders, brighter when focused
+func DefaultStyles() Styles {
+	return Styles{
+		Blurred: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#3b4261")),
+		Focused: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#7aa2f7")),
+	}
+}
 
-func drawTopBorder(title string, width int, focus bool) string {
-	lineStyle := lipgloss.NewStyle().Foreground(paneBorderBlurColor)
-	if focus {
-		lineStyle = lipgloss.NewStyle().Foreground(paneBorderFocusColor)
+func (p Pane) style() lipgloss.Style {
+	if p.Focused {
+		return p.Styles.Focused
 	}
+	return p.Styles.Blurred
+}
+
+func drawTopBorder(title string, width int, style lipgloss.Style) string {
+	border := style.GetBorderStyle()
+	lineStyle := lipgloss.NewStyle().Foreground(style.GetBorderTopForeground())
 
-	leftCorner := lineStyle.Render("╭─")
+	leftCorner := lineStyle.Render(border.TopLeft + border.Top)
 	label := lineStyle.Render(title)
 
 	usedWidth := lipgloss.Width(leftCorner) + lipgloss.Width(label) + 1 // +1 for right corner
-	rightLine := lineStyle.Render(strings.Repeat("─", max(width-usedWidth, 1)) + "╮")
+	rightLine := lineStyle.Render(strings.Repeat(border.Top, max(width-usedWidth, 1)) + border.TopRight)
 
 	return leftCorner + label + rightLine
 }
 
 func (p Pane) Render() string {
-	border := paneBorderBlur
-
-	if p.Focused {
-		border = paneBorderFocus
-	}
+	style := p.style()
+	// the top border is drawn with the title
+	paneStyle := style.BorderTop(false)
 
 	view := p.View()
-	content := border.Width(p.Width).Height(lipgloss.Height(view)).Render(view)
+	content := paneStyle.Width(p.Width).Height(lipgloss.Height(view)).Render(view)
 
-	return lipgloss.JoinVertical(lipgloss.Left, drawTopBorder(p.Title, p.Width+2, p.Focused), content)
+	return lipgloss.JoinVertical(lipgloss.Left, drawTopBorder(p.Title, p.Width+2, style), content)
 }
@@ -0,0 +1,198 @@
+// Package report renders the entries of a period through text/template
+// files: the user's in ~/.ttimelog/templates and the bundled ones. Report
+// is the data the templates get, Funcs the functions they can call.
+package report
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// Report is the data of a template, a period of whole days
+type Report struct {
+	Title string
+	// From is the first day of the period, To the last one
+	From, To  time.Time
+	Generated time.Time
+	// Work and Slack are the totals of the period. Target is the sum of
+	// the days' targets.
+	Work, Slack, Target time.Duration
+	// Days are all days of the period, also those without entries
+	Days []Day
+	// Projects are the work per project, largest first
+	Projects []Project
+	// Entries are the period's entries with a duration, in order
+	Entries []Entry
+}
+
+// Day is a day of the period
+type Day struct {
+	Date                time.Time
+	Work, Slack, Target time.Duration
+	// Off is a weekend or holiday, it has no target
+	Off      bool
+	Projects []Project
+	Entries  []Entry
+}
+
+// Project is the work on a project, or on a category when the report is
+// by category
+type Project struct {
+	Name string
+	Work time.Duration
+	// Percent is the share of the work of the period or day
+	Percent int
+	// Tasks are the distinct descriptions logged, largest first
+	Tasks []Task
+}
+
+// Task is the work on one description of a project
+type Task struct {
+	Name    string
+	Work    time.Duration
+	Entries []Entry
+}
+
+// Entry is a logged task
+type Entry struct {
+	Start, End time.Time
+	Duration   time.Duration
+	// Description is the whole line, Project and Task its parts. Project
+	// is "(no project)" when the description has none.
+	Description   string
+	Project, Task string
+	Slack         bool
+}
+
+// Options are how the period is summarized
+type Options struct {
+	Title string
+	// Target is the work expected on a working day
+	Target time.Duration
+	// IsHoliday tells the days off besides weekends, optional
+	IsHoliday func(time.Time) bool
+	// Key groups entries into projects, timelog.ProjectOf by default
+	Key func(description string) string
+}
+
+// New computes the report of the entries ending in [from, to), which are
+// the starts of days
+func New(entries []timelog.Entry, from, to time.Time, options Options, now time.Time) Report {
+	if options.Key == nil {
+		options.Key = timelog.ProjectOf
+	}
+	entries = timelog.EntriesBetween(entries, from, to)
+	report := Report{
+		Title:     options.Title,
+		From:      from,
+		To:        to.AddDate(0, 0, -1),
+		Generated: now,
+		Days:      []Day{},
+		Projects:  summarize(entries, options.Key),
+		Entries:   newEntries(entries),
+	}
+
+	byDay := make(map[string][]timelog.Entry)
+	for _, entry := range entries {
+		key := timelog.DateKey(entry.EndTime)
+		byDay[key] = append(byDay[key], entry)
+	}
+	for date := from; date.Before(to); date = date.AddDate(0, 0, 1) {
+		dayEntries := byDay[timelog.DateKey(date)]
+		stats := timelog.SummarizeDays(dayEntries)[timelog.DateKey(date)]
+		day := Day{
+			Date:     date,
+			Work:     stats.Work,
+			Slack:    stats.Slack,
+			Off:      date.Weekday() == time.Saturday || date.Weekday() == time.Sunday,
+			Projects: summarize(dayEntries, options.Key),
+			Entries:  newEntries(dayEntries),
+		}
+		if options.IsHoliday != nil && options.IsHoliday(date) {
+			day.Off = true
+		}
+		if !day.Off {
+			day.Target = options.Target
+		}
+		report.Work += day.Work
+		report.Slack += day.Slack
+		report.Target += day.Target
+		report.Days = append(report.Days, day)
+	}
+	return report
+}
+
+func newEntries(entries []timelog.Entry) []Entry {
+	converted := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Duration == 0 {
+			// "arrived" and other markers of a point in time
+			continue
+		}
+		converted = append(converted, Entry{
+			Start:       entry.EndTime.Add(-entry.Duration),
+			End:         entry.EndTime,
+			Duration:    entry.Duration,
+			Description: entry.Description,
+			Project:     timelog.ProjectOf(entry.Description),
+			Task:        taskIn(timelog.ProjectOf(entry.Description), entry.Description),
+			Slack:       timelog.IsSlackEntry(entry),
+		})
+	}
+	return converted
+}
+
+// summarize sums the work of the entries per key and per task
+func summarize(entries []timelog.Entry, key func(string) string) []Project {
+	var work time.Duration
+	for _, entry := range entries {
+		if !timelog.IsSlackEntry(entry) {
+			work += entry.Duration
+		}
+	}
+
+	projects := make([]Project, 0)
+	for _, total := range timelog.SummarizeBy(entries, key) {
+		project := Project{Name: total.Name, Work: total.Duration}
+		if work > 0 {
+			project.Percent = int(100 * total.Duration / work)
+		}
+		tasks := make(map[string]*Task)
+		for _, entry := range entries {
+			if timelog.IsSlackEntry(entry) || entry.Duration == 0 || key(entry.Description) != total.Name {
+				continue
+			}
+			name := taskIn(total.Name, entry.Description)
+			if tasks[name] == nil {
+				tasks[name] = &Task{Name: name}
+			}
+			tasks[name].Work += entry.Duration
+			tasks[name].Entries = append(tasks[name].Entries, newEntries([]timelog.Entry{entry})...)
+		}
+		for _, task := range tasks {
+			project.Tasks = append(project.Tasks, *task)
+		}
+		slices.SortFunc(project.Tasks, func(a, b Task) int {
+			if c := cmp.Compare(b.Work, a.Work); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Name, b.Name)
+		})
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// taskIn is the description without the project or category name, "Acme"
+// leaves "Web: fix login" of "Acme:Web: fix login"
+func taskIn(name, description string) string {
+	description = strings.TrimSpace(description)
+	if task, found := strings.CutPrefix(description, name); found {
+		return strings.TrimLeft(task, ": ")
+	}
+	return description
+}
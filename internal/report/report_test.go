@@ -0,0 +1,116 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+var monday = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func testEntries() []timelog.Entry {
+	return []timelog.Entry{
+		timelog.NewEntry(monday.Add(9*time.Hour), "arrived", 0),
+		timelog.NewEntry(monday.Add(11*time.Hour), "Acme:Web: fix login", 2*time.Hour),
+		timelog.NewEntry(monday.Add(12*time.Hour), "lunch **", time.Hour),
+		timelog.NewEntry(monday.Add(14*time.Hour), "Acme:Web: fix login", 2*time.Hour),
+		timelog.NewEntry(monday.Add(16*time.Hour), "Acme:Ops: deploy", 2*time.Hour),
+		// the next week
+		timelog.NewEntry(monday.AddDate(0, 0, 7).Add(10*time.Hour), "Acme:Web: review", time.Hour),
+	}
+}
+
+func TestNew(t *testing.T) {
+	holiday := monday.AddDate(0, 0, 2)
+	report := New(testEntries(), monday, monday.AddDate(0, 0, 7), Options{
+		Title:     "Week 11",
+		Target:    8 * time.Hour,
+		IsHoliday: func(day time.Time) bool { return day.Equal(holiday) },
+	}, monday.AddDate(0, 0, 5))
+
+	assert.Equal(t, monday.AddDate(0, 0, 6), report.To)
+	assert.Equal(t, 6*time.Hour, report.Work)
+	assert.Equal(t, time.Hour, report.Slack)
+	// five weekdays but one holiday
+	assert.Equal(t, 32*time.Hour, report.Target)
+	assert.Len(t, report.Days, 7)
+	assert.True(t, report.Days[2].Off)
+	assert.True(t, report.Days[6].Off)
+	assert.Equal(t, time.Duration(0), report.Days[6].Target)
+	assert.Len(t, report.Entries, 4)
+	assert.Equal(t, "Acme:Web", report.Entries[0].Project)
+	assert.Equal(t, "fix login", report.Entries[0].Task)
+	assert.Equal(t, monday.Add(9*time.Hour), report.Entries[0].Start)
+
+	assert.Len(t, report.Projects, 2)
+	web := report.Projects[0]
+	assert.Equal(t, "Acme:Web", web.Name)
+	assert.Equal(t, 4*time.Hour, web.Work)
+	assert.Equal(t, 66, web.Percent)
+	assert.Equal(t, []string{"fix login"}, taskNames(web.Tasks))
+	assert.Len(t, web.Tasks[0].Entries, 2)
+	assert.Equal(t, report.Projects, report.Days[0].Projects)
+
+	byCategory := New(testEntries(), monday, monday.AddDate(0, 0, 7), Options{Key: timelog.CategoryOf}, monday)
+	assert.Len(t, byCategory.Projects, 1)
+	assert.Equal(t, []string{"Web: fix login", "Ops: deploy"}, taskNames(byCategory.Projects[0].Tasks))
+}
+
+func taskNames(tasks []Task) []string {
+	names := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	return names
+}
+
+func TestFuncs(t *testing.T) {
+	dir := t.TempDir()
+	content := `{{duration .Work}} {{hours .Work | round 2}} {{hhmm .Work}} {{clock .From}} {{date "Mon 02" .From}}`
+	if err := os.WriteFile(filepath.Join(dir, "short.tmpl"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	tmpl, err := Load(dir, "short")
+	assert.NoError(t, err)
+
+	var out strings.Builder
+	assert.NoError(t, Render(&out, tmpl, Report{From: monday.Add(9 * time.Hour), Work: 7*time.Hour + 20*time.Minute}))
+	assert.Equal(t, "7h20m 7.33 7:20 09:00 Mon 10", out.String())
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	names, err := Names(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email", "markdown", "org"}, names)
+
+	report := New(testEntries(), monday, monday.AddDate(0, 0, 7), Options{Title: "Week 11", Target: 8 * time.Hour}, monday)
+	for _, name := range names {
+		tmpl, err := Load(dir, name)
+		assert.NoError(t, err)
+		var out strings.Builder
+		assert.NoError(t, Render(&out, tmpl, report), name)
+		assert.Contains(t, out.String(), "Week 11", name)
+		assert.Contains(t, out.String(), "Acme:Web", name)
+	}
+
+	// a file of the same name replaces the bundled one
+	if err := os.WriteFile(filepath.Join(dir, "markdown.tmpl"), []byte("mine"), 0o644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+	tmpl, err := Load(dir, "markdown")
+	assert.NoError(t, err)
+	var out strings.Builder
+	assert.NoError(t, Render(&out, tmpl, report))
+	assert.Equal(t, "mine", out.String())
+
+	_, err = Load(dir, "timesheet")
+	assert.ErrorContains(t, err, "available: email, markdown, org")
+	_, err = Load(dir, filepath.Join(dir, "missing.tmpl"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// templateExt is the extension of template files, optional on the
+// command line
+const templateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var bundled embed.FS
+
+// Funcs are the functions available to templates besides the built-in ones
+var Funcs = template.FuncMap{
+	// duration formats like the rest of ttimelog, "7h30m"
+	"duration": timelog.FormatStatDuration,
+	// hours is the decimal hours of a duration, usually piped into round
+	"hours": func(d time.Duration) float64 {
+		return d.Hours()
+	},
+	// round rounds to places decimal places: {{hours .Work | round 2}}
+	"round": func(places int, x float64) float64 {
+		scale := math.Pow(10, float64(places))
+		return math.Round(x*scale) / scale
+	},
+	// hhmm formats a duration as "7:30", the way Org mode clocks do
+	"hhmm": func(d time.Duration) string {
+		d = d.Truncate(time.Minute)
+		return fmt.Sprintf("%d:%02d", d/time.Hour, d%time.Hour/time.Minute)
+	},
+	// clock is the time of day, "09:00"
+	"clock": func(t time.Time) string {
+		return t.Format("15:04")
+	},
+	// date formats with a Go layout: {{date "Mon 02 Jan" .Date}}
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// Load parses the template name: a file path, a file in dir with or
+// without ".tmpl", or a bundled template. Files in dir take precedence over
+// bundled templates of the same name.
+func Load(dir, name string) (*template.Template, error) {
+	candidates := []string{filepath.Join(dir, name+templateExt), filepath.Join(dir, name)}
+	if strings.ContainsRune(name, filepath.Separator) {
+		candidates = []string{name}
+	}
+	for _, path := range candidates {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template[%s] with error[%v]", path, err)
+		}
+		tmpl, err := template.New(filepath.Base(path)).Funcs(Funcs).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %s: %w", path, err)
+		}
+		return tmpl, nil
+	}
+
+	content, err := bundled.ReadFile("templates/" + strings.TrimSuffix(name, templateExt) + templateExt)
+	if err != nil {
+		names, _ := Names(dir)
+		return nil, fmt.Errorf("no template %q, available: %s", name, strings.Join(names, ", "))
+	}
+	return template.New(name).Funcs(Funcs).Parse(string(content))
+}
+
+// Names lists the bundled templates and those in dir
+func Names(dir string) ([]string, error) {
+	var names []string
+	entries, err := bundled.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), templateExt))
+	}
+
+	entries, err = os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return names, err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), templateExt)
+		if entry.Type().IsRegular() && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Render executes tmpl with the report
+func Render(w io.Writer, tmpl *template.Template, report Report) error {
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// REST reads projects from any JSON endpoint, e.g. the issues of GitLab,
+// Jira or Redmine, picking the project path and metadata out of every item
+// by the configured field mapping. The response is cached like the
+// Chronophage project list.
+type REST struct {
+	config config.SourceConfig
+	client *http.Client
+	// cachePath keeps the last response, used for ttl and when the endpoint
+	// can't be reached
+	cachePath string
+	ttl       time.Duration
+}
+
+func NewREST(sourceConfig config.SourceConfig, appConfig *config.AppConfig) *REST {
+	ttl := appConfig.Gtimelog.ProjectListTTL
+	if ttl <= 0 {
+		ttl = chrono.DefaultProjectListTTL
+	}
+	return &REST{
+		config: sourceConfig,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cachePath: filepath.Join(appConfig.TimeLogDirPath, fmt.Sprintf(config.SourceCacheFile, sourceConfig.Name)),
+		ttl:       ttl,
+	}
+}
+
+func (r *REST) Label() string {
+	return r.config.Label
+}
+
+// Load fetches the projects when the cached response is older than the
+// project list TTL or with force, falling back to the cached response when
+// the fetch fails
+func (r *REST) Load(ctx context.Context, force bool) ([]chrono.Project, error) {
+	info, statErr := os.Stat(r.cachePath)
+	if statErr == nil && !force && time.Since(info.ModTime()) < r.ttl {
+		slog.Debug("Cached projects are fresh, not fetching", "source", r.config.Name, "fetchedAt", info.ModTime())
+		return r.loadCache()
+	}
+
+	projects, fetchErr := r.fetch(ctx)
+	if fetchErr == nil {
+		return projects, nil
+	}
+	if statErr != nil {
+		return nil, fetchErr
+	}
+	projects, err := r.loadCache()
+	return projects, errors.Join(fetchErr, err)
+}
+
+func (r *REST) loadCache() ([]chrono.Project, error) {
+	body, err := os.ReadFile(r.cachePath)
+	if err != nil {
+		return nil, err
+	}
+	return r.parse(body)
+}
+
+// fetch downloads the projects and caches the response when it parses
+func (r *REST) fetch(ctx context.Context) ([]chrono.Project, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.config.AuthHeader != "" {
+		req.Header.Add("Authorization", r.config.AuthHeader.Reveal())
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects from [%s] with error[%v]", r.config.URL, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching projects failed with status[%s]", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects with error[%v]", err)
+	}
+	projects, err := r.parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := timelog.WriteFileAtomic(r.cachePath, body); err != nil {
+		slog.Error("Failed to cache projects", "filePath", r.cachePath, "error", err)
+	}
+	return projects, nil
+}
+
+func (r *REST) parse(body []byte) ([]chrono.Project, error) {
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse projects with error[%v]", err)
+	}
+
+	items, ok := lookup(document, r.config.Items).([]any)
+	if !ok {
+		return nil, fmt.Errorf("items[%s] is not an array in the response", r.config.Items)
+	}
+
+	separator := r.config.PathSeparator
+	if separator == "" {
+		separator = ":"
+	}
+
+	projects := make([]chrono.Project, 0, len(items))
+	for _, item := range items {
+		path := make([]string, 0, len(r.config.PathFields))
+		for _, field := range r.config.PathFields {
+			for _, level := range strings.Split(stringValue(lookup(item, field)), separator) {
+				if level = strings.TrimSpace(level); level != "" {
+					path = append(path, level)
+				}
+			}
+		}
+		if len(path) == 0 {
+			continue
+		}
+
+		project := chrono.Project{
+			Path:        path,
+			Code:        field(item, r.config.CodeField),
+			Description: field(item, r.config.DescriptionField),
+		}
+		if activeField, negated := strings.CutPrefix(r.config.ActiveField, "!"); activeField != "" {
+			// "!archived" reads a flag that is set for inactive projects
+			project.Inactive = isActive(lookup(item, activeField)) == negated
+		}
+		projects = append(projects, project)
+	}
+
+	if len(projects) == 0 && len(items) > 0 {
+		return nil, errors.New("no item has the configured path_fields")
+	}
+	return projects, nil
+}
+
+// lookup follows a dotted path such as "fields.project.name" into decoded
+// JSON, array elements are addressed by their index
+func lookup(value any, path string) any {
+	if path == "" {
+		return value
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil
+			}
+			value = v[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// field returns the value of an optional mapped field as text
+func field(item any, path string) string {
+	if path == "" {
+		return ""
+	}
+	return stringValue(lookup(item, path))
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// isActive treats booleans as is and strings such as "closed" or "archived"
+// as states, everything else counts as active
+func isActive(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(v) {
+		case "false", "closed", "done", "archived", "inactive", "resolved":
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// Package source loads project lists from the configured project sources
+// and merges them into one tree
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/Rash419/ttimelog/internal/treeview"
+)
+
+// Source provides projects, e.g. from a file or a web service
+type Source interface {
+	// Label is the name of the root node of the source's projects
+	Label() string
+	// Load returns the projects, refreshing a cached copy when force is set.
+	// It may return projects together with an error, e.g. stale ones when
+	// the refresh failed.
+	Load(ctx context.Context, force bool) ([]chrono.Project, error)
+}
+
+// FromConfig creates the sources of the [source.*] sections. Without any,
+// the Chronophage project list of [gtimelog] is used as before.
+func FromConfig(appConfig *config.AppConfig) []Source {
+	if len(appConfig.Sources) == 0 {
+		return []Source{NewChronophage("Chronophage", appConfig)}
+	}
+
+	sources := make([]Source, 0, len(appConfig.Sources))
+	for _, sourceConfig := range appConfig.Sources {
+		switch sourceConfig.Type {
+		case config.SourceFile:
+			path := sourceConfig.Path
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(appConfig.TimeLogDirPath, path)
+			}
+			sources = append(sources, NewFile(sourceConfig.Label, path, sourceConfig.Format))
+		case config.SourceChronophage:
+			sources = append(sources, NewChronophage(sourceConfig.Label, appConfig))
+		case config.SourceREST:
+			sources = append(sources, NewREST(sourceConfig, appConfig))
+		}
+	}
+	return sources
+}
+
+// LoadAll loads all sources concurrently. A single source makes up the whole
+// tree, several are put below roots named by their labels. Errors of the
+// individual sources are joined, the tree holds whatever could be loaded.
+func LoadAll(ctx context.Context, sources []Source, force bool) (*treeview.TreeNode, error) {
+	results := make([][]chrono.Project, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = source.Load(ctx, force)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", source.Label(), errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(sources) == 1 {
+		return chrono.BuildProjectTree(results[0]), errors.Join(errs...)
+	}
+
+	hiddenRoot := &treeview.TreeNode{
+		Expanded: true,
+		Label:    "Projects",
+	}
+	for i, source := range sources {
+		if len(results[i]) == 0 {
+			continue
+		}
+		sourceRoot := chrono.BuildProjectTree(results[i])
+		sourceRoot.Label = source.Label()
+		sourceRoot.Expanded = false
+		hiddenRoot.Children = append(hiddenRoot.Children, sourceRoot)
+	}
+	return hiddenRoot, errors.Join(errs...)
+}
+
+// File reads projects from a local file
+type File struct {
+	label  string
+	path   string
+	format string
+}
+
+func NewFile(label, path, format string) *File {
+	return &File{label: label, path: path, format: format}
+}
+
+func (f *File) Label() string {
+	return f.label
+}
+
+func (f *File) Load(ctx context.Context, force bool) ([]chrono.Project, error) {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return chrono.ParseProjects(f.format, "", content)
+}
+
+// Chronophage downloads the project list of [gtimelog] into project-list.txt
+type Chronophage struct {
+	label     string
+	appConfig *config.AppConfig
+}
+
+func NewChronophage(label string, appConfig *config.AppConfig) *Chronophage {
+	return &Chronophage{label: label, appConfig: appConfig}
+}
+
+func (c *Chronophage) Label() string {
+	return c.label
+}
+
+// Load refreshes project-list.txt when it's stale and reads it, falling back
+// to the cached list when the download fails
+func (c *Chronophage) Load(ctx context.Context, force bool) ([]chrono.Project, error) {
+	_, fetchErr := chrono.FetchProjectList(ctx, c.appConfig, force)
+	if errors.Is(fetchErr, chrono.ErrNoTaskListURL) {
+		// a project list put in place by hand is still used
+		fetchErr = nil
+	}
+
+	cachePath := filepath.Join(c.appConfig.TimeLogDirPath, config.ProjectListFile)
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, errors.Join(fetchErr, err)
+	}
+	projects, err := chrono.ParseProjects(c.appConfig.Gtimelog.ProjectListFormat, "", content)
+	return projects, errors.Join(fetchErr, err)
+}
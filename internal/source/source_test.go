@@ -0,0 +1,175 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rash419/ttimelog/internal/chrono"
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const jiraResponse = `{
+  "issues": [
+    {"key": "WEB-1", "fields": {"project": {"name": "Website"}, "summary": "Login page", "status": {"name": "Open"}}},
+    {"key": "WEB-2", "fields": {"project": {"name": "Website"}, "summary": "Old design", "status": {"name": "Closed"}}}
+  ]
+}`
+
+func jiraSource(url string) config.SourceConfig {
+	return config.SourceConfig{
+		Name:             "jira",
+		Type:             config.SourceREST,
+		Label:            "Jira",
+		URL:              url,
+		AuthHeader:       "Bearer JIRATOKEN",
+		Items:            "issues",
+		PathFields:       []string{"fields.project.name", "key"},
+		CodeField:        "key",
+		DescriptionField: "fields.summary",
+		ActiveField:      "fields.status.name",
+	}
+}
+
+func testAppConfig(t *testing.T) *config.AppConfig {
+	return &config.AppConfig{TimeLogDirPath: t.TempDir()}
+}
+
+func TestREST(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer JIRATOKEN", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(jiraResponse))
+	}))
+	defer server.Close()
+
+	projects, err := NewREST(jiraSource(server.URL), testAppConfig(t)).Load(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []chrono.Project{
+		{Path: []string{"Website", "WEB-1"}, Code: "WEB-1", Description: "Login page"},
+		{Path: []string{"Website", "WEB-2"}, Code: "WEB-2", Description: "Old design", Inactive: true},
+	}, projects)
+}
+
+func TestRESTArrayWithSeparator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 7, "path_with_namespace": "group/sub/project", "archived": false}]`))
+	}))
+	defer server.Close()
+
+	projects, err := NewREST(config.SourceConfig{
+		Label:         "GitLab",
+		URL:           server.URL,
+		PathFields:    []string{"path_with_namespace"},
+		PathSeparator: "/",
+		CodeField:     "id",
+		ActiveField:   "!archived",
+	}, testAppConfig(t)).Load(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []chrono.Project{{Path: []string{"group", "sub", "project"}, Code: "7"}}, projects)
+}
+
+func TestRESTErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/unauthorized":
+			http.Error(w, "no", http.StatusUnauthorized)
+		case "/object":
+			_, _ = w.Write([]byte(`{"issues": {}}`))
+		default:
+			_, _ = w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/unauthorized", "/object", "/garbage"} {
+		_, err := NewREST(jiraSource(server.URL+path), testAppConfig(t)).Load(context.Background(), false)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestRESTCache(t *testing.T) {
+	requests := 0
+	online := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !online {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(jiraResponse))
+	}))
+	defer server.Close()
+
+	appConfig := testAppConfig(t)
+	source := NewREST(jiraSource(server.URL), appConfig)
+	projects, err := source.Load(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Len(t, projects, 2)
+	assert.FileExists(t, filepath.Join(appConfig.TimeLogDirPath, "source-jira.json"))
+
+	// a fresh cache is used without asking
+	cached, err := source.Load(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, projects, cached)
+	assert.Equal(t, 1, requests)
+
+	// the cache is kept when the endpoint fails
+	online = false
+	cached, err = source.Load(context.Background(), true)
+	assert.ErrorContains(t, err, "502")
+	assert.Equal(t, projects, cached)
+	assert.Equal(t, 2, requests)
+}
+
+func TestLoadAll(t *testing.T) {
+	chronophage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("Acme:Web:Backend:Dev\n"))
+	}))
+	defer chronophage.Close()
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(jiraResponse))
+	}))
+	defer jira.Close()
+
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	appConfig.Gtimelog.TaskListURL = chronophage.URL
+	localList := filepath.Join(appConfig.TimeLogDirPath, "local.txt")
+	if err := os.WriteFile(localList, []byte("Home:Garden\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write project list: %v", err)
+	}
+	appConfig.Sources = []config.SourceConfig{
+		{Name: "chronophage", Type: config.SourceChronophage, Label: "Chronophage"},
+		{Name: "local", Type: config.SourceFile, Label: "Local", Path: "local.txt"},
+		jiraSource(jira.URL),
+		{Name: "missing", Type: config.SourceFile, Label: "Missing", Path: "missing.txt"},
+	}
+
+	root, err := LoadAll(context.Background(), FromConfig(appConfig), false)
+	assert.ErrorContains(t, err, "Missing")
+
+	labels := make([]string, 0)
+	for _, child := range root.Children {
+		labels = append(labels, child.Label)
+	}
+	assert.Equal(t, []string{"Chronophage", "Local", "Jira"}, labels)
+	// paths stay relative to the source so entries are logged without the label
+	assert.Equal(t, "Home:Garden", root.Children[1].Children[0].Children[0].Path)
+}
+
+func TestLoadAllSingleSource(t *testing.T) {
+	appConfig := &config.AppConfig{TimeLogDirPath: t.TempDir()}
+	projectList := filepath.Join(appConfig.TimeLogDirPath, config.ProjectListFile)
+	if err := os.WriteFile(projectList, []byte("Acme:Web:Backend:Dev\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write project list: %v", err)
+	}
+
+	// without task_list_url the existing project-list.txt is used as is
+	root, err := LoadAll(context.Background(), FromConfig(appConfig), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme", root.Children[0].Label)
+}
@@ -0,0 +1,306 @@
+// Package sshd is a minimal SSH server for interactive sessions, used to
+// serve the ttimelog interface to other machines. Only public-key logins
+// listed in an authorized_keys file are accepted.
+package sshd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// handshakeTimeout bounds how long a client may take to log in
+const handshakeTimeout = 30 * time.Second
+
+// Size is the terminal size of a session
+type Size struct {
+	Width  int
+	Height int
+}
+
+// Session is an interactive session with a terminal. Reads return the
+// keys typed by the user, writes go to their terminal.
+type Session struct {
+	ssh.Channel
+	User string
+	// Term is the TERM of the client, empty without a terminal
+	Term string
+
+	mu      sync.Mutex
+	size    Size
+	resizes chan Size
+}
+
+// Size is the current terminal size
+func (s *Session) Size() Size {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Resizes delivers the new size when the client's terminal is resized
+func (s *Session) Resizes() <-chan Size {
+	return s.resizes
+}
+
+func (s *Session) resize(size Size) {
+	s.mu.Lock()
+	s.size = size
+	s.mu.Unlock()
+	select {
+	case s.resizes <- size:
+	default:
+		// the handler reads Size when it catches up
+	}
+}
+
+// Handler runs a session, which ends when it returns or ctx is done
+type Handler func(ctx context.Context, session *Session)
+
+// Server serves interactive sessions
+type Server struct {
+	config   *ssh.ServerConfig
+	listener net.Listener
+	handler  Handler
+}
+
+// Listen starts the server on address. The authorized keys are read on every
+// login, so edits take effect without a restart.
+func Listen(address string, hostKey ssh.Signer, authorizedKeysPath string, handler Handler) (*Server, error) {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			authorized, err := LoadAuthorizedKeys(authorizedKeysPath)
+			if err != nil {
+				slog.Error("Failed to read authorized keys", "path", authorizedKeysPath, "error", err)
+				return nil, err
+			}
+			for _, authorizedKey := range authorized {
+				if bytes.Equal(authorizedKey.Marshal(), key.Marshal()) {
+					return &ssh.Permissions{}, nil
+				}
+			}
+			slog.Info("Rejected SSH key", "user", conn.User(), "remote", conn.RemoteAddr(), "fingerprint", ssh.FingerprintSHA256(key))
+			return nil, errors.New("unknown public key")
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on [%s] with error[%v]", address, err)
+	}
+	return &Server{config: config, listener: listener, handler: handler}, nil
+}
+
+// Addr is the address the server listens on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve accepts connections until ctx is done and waits for the sessions
+// to end
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = s.listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		slog.Debug("SSH handshake failed", "remote", conn.RemoteAddr(), "error", err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	slog.Info("SSH login", "user", serverConn.User(), "remote", serverConn.RemoteAddr())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = serverConn.Close()
+	}()
+	go ssh.DiscardRequests(requests)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			slog.Error("Failed to accept SSH channel", "error", err)
+			continue
+		}
+		session := &Session{Channel: channel, User: serverConn.User(), resizes: make(chan Size, 1)}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveSession(ctx, session, channelRequests)
+		}()
+	}
+}
+
+// ptyRequest is the payload of "pty-req", RFC 4254 6.2
+type ptyRequest struct {
+	Term    string
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+	Modes   string
+}
+
+// windowChange is the payload of "window-change", RFC 4254 6.7
+type windowChange struct {
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+}
+
+func (s *Server) serveSession(ctx context.Context, session *Session, requests <-chan *ssh.Request) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	started := false
+	for {
+		select {
+		case request, ok := <-requests:
+			if !ok {
+				// the client went away, stop the handler
+				cancel()
+				if started {
+					<-done
+				}
+				return
+			}
+			ok = true
+			switch request.Type {
+			case "pty-req":
+				var pty ptyRequest
+				if err := ssh.Unmarshal(request.Payload, &pty); err != nil {
+					ok = false
+					break
+				}
+				session.Term = pty.Term
+				session.resize(Size{Width: int(pty.Columns), Height: int(pty.Rows)})
+			case "window-change":
+				var change windowChange
+				if err := ssh.Unmarshal(request.Payload, &change); err != nil {
+					ok = false
+					break
+				}
+				session.resize(Size{Width: int(change.Columns), Height: int(change.Rows)})
+			case "shell":
+				if started || session.Term == "" {
+					// the interface needs a terminal, ssh -t
+					ok = false
+					break
+				}
+				started = true
+				go func() {
+					defer close(done)
+					s.handler(ctx, session)
+					_, _ = session.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+					_ = session.Close()
+				}()
+			case "env":
+				// accepted and ignored, TERM comes with the pty
+			default:
+				ok = false
+			}
+			if request.WantReply {
+				_ = request.Reply(ok, nil)
+			}
+		case <-done:
+			go ssh.DiscardRequests(requests)
+			return
+		}
+	}
+}
+
+// LoadAuthorizedKeys reads the keys of an authorized_keys file, options are
+// ignored
+func LoadAuthorizedKeys(path string) ([]ssh.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []ssh.PublicKey
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in [%s] line %d with error[%v]", path, i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// LoadHostKey reads the host key at path, creating an ed25519 key the first
+// time
+func LoadHostKey(path string) (ssh.Signer, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		block, err := ssh.MarshalPrivateKey(private, "ttimelog host key")
+		if err != nil {
+			return nil, err
+		}
+		content = pem.EncodeToMemory(block)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write host key[%s] with error[%v]", path, err)
+		}
+		slog.Info("Created SSH host key", "path", path)
+	} else if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host key[%s] with error[%v]", path, err)
+	}
+	return signer, nil
+}
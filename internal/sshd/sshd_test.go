@@ -0,0 +1,146 @@
+package sshd
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newClientKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	return signer
+}
+
+// startServer serves an echo of the session's size and first input line
+func startServer(t *testing.T, authorized ...ssh.PublicKey) (*Server, ssh.PublicKey) {
+	t.Helper()
+	dir := t.TempDir()
+	var keys []byte
+	for _, key := range authorized {
+		keys = append(keys, ssh.MarshalAuthorizedKey(key)...)
+	}
+	authorizedKeysPath := filepath.Join(dir, "authorized_keys")
+	if err := os.WriteFile(authorizedKeysPath, append([]byte("# comment\n\n"), keys...), 0o600); err != nil {
+		t.Fatalf("Failed to write authorized keys: %v", err)
+	}
+	hostKey, err := LoadHostKey(filepath.Join(dir, "host_key"))
+	if err != nil {
+		t.Fatalf("Failed to load host key: %v", err)
+	}
+
+	server, err := Listen("127.0.0.1:0", hostKey, authorizedKeysPath, func(ctx context.Context, session *Session) {
+		size := session.Size()
+		fmt.Fprintf(session, "%s %s %dx%d\r\n", session.User, session.Term, size.Width, size.Height)
+		line, _ := bufio.NewReader(session).ReadString('\r')
+		fmt.Fprintf(session, "got %s\n", line)
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return server, hostKey.PublicKey()
+}
+
+func dial(server *Server, hostKey ssh.PublicKey, key ssh.Signer) (*ssh.Client, error) {
+	return ssh.Dial("tcp", server.Addr().String(), &ssh.ClientConfig{
+		User:            "alice",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(key)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	})
+}
+
+func TestSession(t *testing.T) {
+	key := newClientKey(t)
+	server, hostKey := startServer(t, key.PublicKey())
+
+	client, err := dial(server, hostKey, key)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Failed to open session: %v", err)
+	}
+	defer session.Close()
+	assert.NoError(t, session.RequestPty("xterm-256color", 40, 120, ssh.TerminalModes{}))
+	stdin, _ := session.StdinPipe()
+	stdout, _ := session.StdoutPipe()
+	assert.NoError(t, session.Shell())
+
+	reader := bufio.NewReader(stdout)
+	line, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "alice xterm-256color 120x40\r\n", line)
+
+	_, _ = stdin.Write([]byte("hello\r"))
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "got hello\r\n", line)
+	assert.NoError(t, session.Wait())
+}
+
+func TestShellNeedsTerminal(t *testing.T) {
+	key := newClientKey(t)
+	server, hostKey := startServer(t, key.PublicKey())
+
+	client, err := dial(server, hostKey, key)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Failed to open session: %v", err)
+	}
+	defer session.Close()
+	assert.Error(t, session.Shell())
+}
+
+func TestUnknownKey(t *testing.T) {
+	server, hostKey := startServer(t, newClientKey(t).PublicKey())
+
+	_, err := dial(server, hostKey, newClientKey(t))
+	assert.Error(t, err)
+}
+
+func TestLoadHostKeyKeepsKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "host_key")
+	first, err := LoadHostKey(path)
+	assert.NoError(t, err)
+	second, err := LoadHostKey(path)
+	assert.NoError(t, err)
+	assert.Equal(t, first.PublicKey().Marshal(), second.PublicKey().Marshal())
+
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
@@ -0,0 +1,105 @@
+// Package standup summarizes the previous working day and today for a
+// daily standup: the distinct tasks per project with their durations.
+package standup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/report"
+	"github.com/Rash419/ttimelog/internal/timelog"
+)
+
+// lookback bounds the search for the previous working day with entries,
+// e.g. after a vacation
+const lookback = 31
+
+// Day is the work of one day, slack left out
+type Day struct {
+	Date     time.Time
+	Work     time.Duration
+	Projects []report.Project
+}
+
+// Standup is the previous working day and today so far
+type Standup struct {
+	Previous Day
+	Today    Day
+}
+
+// New summarizes the working day before now, skipping weekends and
+// holidays, and today's entries until now. After days off without entries,
+// e.g. a vacation, the last working day with entries is used instead.
+func New(entries []timelog.Entry, now time.Time, isHoliday func(time.Time) bool) Standup {
+	today := timelog.StartOfDay(now)
+	previous := previousWorkingDay(today, isHoliday)
+	for day, i := previous, 0; i < lookback; day, i = previousWorkingDay(day, isHoliday), i+1 {
+		if len(timelog.EntriesBetween(entries, day, day.AddDate(0, 0, 1))) > 0 {
+			previous = day
+			break
+		}
+	}
+	return Standup{
+		Previous: newDay(entries, previous),
+		Today:    newDay(entries, today),
+	}
+}
+
+func newDay(entries []timelog.Entry, date time.Time) Day {
+	summary := report.New(entries, date, date.AddDate(0, 0, 1), report.Options{}, date)
+	return Day{Date: date, Work: summary.Work, Projects: summary.Projects}
+}
+
+func previousWorkingDay(day time.Time, isHoliday func(time.Time) bool) time.Time {
+	for i := 0; i < lookback; i++ {
+		day = day.AddDate(0, 0, -1)
+		if isWorkingDay(day, isHoliday) {
+			break
+		}
+	}
+	return day
+}
+
+func isWorkingDay(day time.Time, isHoliday func(time.Time) bool) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return isHoliday == nil || !isHoliday(day)
+}
+
+// Text is the standup as plain text to paste into a chat:
+//
+//	Yesterday (Mon 10 Mar, 6h0m)
+//	- Acme:Web (4h0m)
+//	  - fix login (3h0m)
+//	  - review (1h0m)
+//
+//	Today (1h30m so far)
+//	- Acme:Ops (1h30m)
+//	  - deploy (1h30m)
+func (s Standup) Text() string {
+	var text strings.Builder
+	label := s.Previous.Date.Format("Monday")
+	if s.Previous.Date.Equal(s.Today.Date.AddDate(0, 0, -1)) {
+		label = "Yesterday"
+	}
+	fmt.Fprintf(&text, "%s (%s, %s)\n", label, s.Previous.Date.Format("Mon 02 Jan"), timelog.FormatStatDuration(s.Previous.Work))
+	writeProjects(&text, s.Previous.Projects)
+	fmt.Fprintf(&text, "\nToday (%s so far)\n", timelog.FormatStatDuration(s.Today.Work))
+	writeProjects(&text, s.Today.Projects)
+	return text.String()
+}
+
+func writeProjects(text *strings.Builder, projects []report.Project) {
+	if len(projects) == 0 {
+		text.WriteString("- nothing logged\n")
+		return
+	}
+	for _, project := range projects {
+		fmt.Fprintf(text, "- %s (%s)\n", project.Name, timelog.FormatStatDuration(project.Work))
+		for _, task := range project.Tasks {
+			fmt.Fprintf(text, "  - %s (%s)\n", task.Name, timelog.FormatStatDuration(task.Work))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package standup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rash419/ttimelog/internal/timelog"
+	"github.com/stretchr/testify/assert"
+)
+
+var friday = time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+
+func testEntries() []timelog.Entry {
+	monday := friday.AddDate(0, 0, 3)
+	return []timelog.Entry{
+		timelog.NewEntry(friday.Add(9*time.Hour), "arrived", 0),
+		timelog.NewEntry(friday.Add(11*time.Hour), "Acme:Web: fix login", 2*time.Hour),
+		timelog.NewEntry(friday.Add(12*time.Hour), "lunch **", time.Hour),
+		timelog.NewEntry(friday.Add(13*time.Hour), "Acme:Web: fix login", time.Hour),
+		timelog.NewEntry(friday.Add(14*time.Hour), "Acme:Web: review", time.Hour),
+		// weekend work is skipped
+		timelog.NewEntry(friday.AddDate(0, 0, 1).Add(10*time.Hour), "Acme:Ops: pager", time.Hour),
+		timelog.NewEntry(monday.Add(9*time.Hour), "arrived", 0),
+		timelog.NewEntry(monday.Add(10*time.Hour+30*time.Minute), "Acme:Ops: deploy", 90*time.Minute),
+	}
+}
+
+func TestNew(t *testing.T) {
+	monday := friday.AddDate(0, 0, 3)
+	standup := New(testEntries(), monday.Add(11*time.Hour), nil)
+	assert.Equal(t, friday, standup.Previous.Date)
+	assert.Equal(t, 4*time.Hour, standup.Previous.Work)
+	assert.Equal(t, monday, standup.Today.Date)
+
+	assert.Equal(t, `Friday (Fri 07 Mar, 4h0m)
+- Acme:Web (4h0m)
+  - fix login (3h0m)
+  - review (1h0m)
+
+Today (1h30m so far)
+- Acme:Ops (1h30m)
+  - deploy (1h30m)
+`, standup.Text())
+}
+
+func TestNewSkipsDaysOff(t *testing.T) {
+	tuesday := friday.AddDate(0, 0, 4)
+	monday := friday.AddDate(0, 0, 3)
+
+	standup := New(testEntries(), tuesday.Add(9*time.Hour), nil)
+	assert.Equal(t, monday, standup.Previous.Date)
+	assert.Contains(t, standup.Text(), "Yesterday (Mon 10 Mar, 1h30m)")
+	assert.Contains(t, standup.Text(), "Today (0h0m so far)\n- nothing logged\n")
+
+	// Monday was a holiday
+	standup = New(testEntries(), tuesday.Add(9*time.Hour), func(day time.Time) bool { return day.Equal(monday) })
+	assert.Equal(t, friday, standup.Previous.Date)
+
+	// a week off without entries
+	standup = New(testEntries(), tuesday.AddDate(0, 0, 14), nil)
+	assert.Equal(t, monday, standup.Previous.Date)
+
+	// nothing in the last weeks
+	standup = New(testEntries(), tuesday.AddDate(0, 3, 0), nil)
+	assert.Equal(t, tuesday.AddDate(0, 3, -1), standup.Previous.Date)
+	assert.Empty(t, standup.Previous.Projects)
+}
@@ -0,0 +1,236 @@
+// Package theme holds the colour palettes used to render the TUI
+package theme
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Rash419/ttimelog/internal/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Theme struct {
+	Name string
+	// Monochrome themes can't rely on colour, focus is shown with a thicker border
+	Monochrome bool
+
+	BorderBlur  lipgloss.Color
+	BorderFocus lipgloss.Color
+	// Muted is used for dividers, empty cells and hints
+	Muted      lipgloss.Color
+	Accent     lipgloss.Color
+	SelectedFg lipgloss.Color
+	SelectedBg lipgloss.Color
+	Success    lipgloss.Color
+	Warning    lipgloss.Color
+	Danger     lipgloss.Color
+
+	ProgressStart lipgloss.Color
+	ProgressEnd   lipgloss.Color
+
+	// Levels are shades from "a little" to "target reached" for the heatmap
+	Levels []lipgloss.Color
+}
+
+const (
+	Auto         = "auto"
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+	Solarized    = "solarized"
+	NoColor      = "no-color"
+)
+
+var builtin = map[string]Theme{
+	Dark: {
+		Name:          Dark,
+		BorderBlur:    "#3b4261",
+		BorderFocus:   "#7aa2f7",
+		Muted:         "240",
+		Accent:        "#7aa2f7",
+		SelectedFg:    "212",
+		Success:       "#39d353",
+		Warning:       "#e0af68",
+		Danger:        "#f7768e",
+		ProgressStart: "#5A56E0",
+		ProgressEnd:   "#EE6FF8",
+		Levels:        []lipgloss.Color{"#0e4429", "#006d32", "#26a641", "#39d353"},
+	},
+	Light: {
+		Name:          Light,
+		BorderBlur:    "#a8aecb",
+		BorderFocus:   "#2e7de9",
+		Muted:         "#8990b3",
+		Accent:        "#2e7de9",
+		SelectedFg:    "#9854f1",
+		Success:       "#216e39",
+		Warning:       "#8c6c3e",
+		Danger:        "#c64343",
+		ProgressStart: "#2e7de9",
+		ProgressEnd:   "#9854f1",
+		Levels:        []lipgloss.Color{"#9be9a8", "#40c463", "#30a14e", "#216e39"},
+	},
+	HighContrast: {
+		Name:          HighContrast,
+		BorderBlur:    "#808080",
+		BorderFocus:   "#ffff00",
+		Muted:         "#c0c0c0",
+		Accent:        "#00ffff",
+		SelectedFg:    "#000000",
+		SelectedBg:    "#ffff00",
+		Success:       "#00ff00",
+		Warning:       "#ffff00",
+		Danger:        "#ff0000",
+		ProgressStart: "#00ff00",
+		ProgressEnd:   "#00ff00",
+		Levels:        []lipgloss.Color{"#005f00", "#008700", "#00d700", "#00ff00"},
+	},
+	Solarized: {
+		Name:          Solarized,
+		BorderBlur:    "#586e75",
+		BorderFocus:   "#268bd2",
+		Muted:         "#657b83",
+		Accent:        "#268bd2",
+		SelectedFg:    "#d33682",
+		Success:       "#859900",
+		Warning:       "#b58900",
+		Danger:        "#dc322f",
+		ProgressStart: "#268bd2",
+		ProgressEnd:   "#2aa198",
+		Levels:        []lipgloss.Color{"#073642", "#586e75", "#2aa198", "#859900"},
+	},
+	NoColor: {
+		Name:       NoColor,
+		Monochrome: true,
+		Levels:     []lipgloss.Color{"", "", "", ""},
+	},
+}
+
+// Names returns the names of the built-in themes
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Environment describes the terminal a theme is resolved for
+type Environment struct {
+	NoColor           bool
+	HasDarkBackground bool
+}
+
+// DetectEnvironment checks NO_COLOR and queries the terminal background.
+// It has to be called before the Bubble Tea program takes over the terminal.
+func DetectEnvironment() Environment {
+	return Environment{
+		NoColor:           os.Getenv("NO_COLOR") != "",
+		HasDarkBackground: lipgloss.HasDarkBackground(),
+	}
+}
+
+// Resolve returns the built-in theme with the given name, "auto" (or empty)
+// picks dark or light from the terminal background. NO_COLOR always wins.
+func Resolve(name string, env Environment) (Theme, error) {
+	if env.NoColor {
+		return builtin[NoColor], nil
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == Auto {
+		if env.HasDarkBackground {
+			return builtin[Dark], nil
+		}
+		return builtin[Light], nil
+	}
+
+	t, ok := builtin[name]
+	if !ok {
+		return builtin[Dark], fmt.Errorf("unknown theme[%s], available themes are %s", name, strings.Join(Names(), ", "))
+	}
+	return t, nil
+}
+
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// WithOverrides replaces colours of the theme, keys are the ttimelogrc names
+// (border_focus, accent, ...). Empty values keep the theme colour. On error
+// the theme is returned unchanged, the error is about the first invalid key
+// in sorted order.
+func (t Theme) WithOverrides(overrides map[string]string) (Theme, error) {
+	if t.Monochrome {
+		return t, nil
+	}
+	original := t
+
+	fields := map[string]*lipgloss.Color{
+		"border_blur":    &t.BorderBlur,
+		"border_focus":   &t.BorderFocus,
+		"muted":          &t.Muted,
+		"accent":         &t.Accent,
+		"selected_fg":    &t.SelectedFg,
+		"selected_bg":    &t.SelectedBg,
+		"success":        &t.Success,
+		"warning":        &t.Warning,
+		"danger":         &t.Danger,
+		"progress_start": &t.ProgressStart,
+		"progress_end":   &t.ProgressEnd,
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(overrides)) {
+		value := overrides[key]
+		if value == "" {
+			continue
+		}
+		field, ok := fields[key]
+		if !ok {
+			return original, fmt.Errorf("unknown theme colour[%s]", key)
+		}
+		if !colorPattern.MatchString(value) {
+			return original, fmt.Errorf("invalid colour[%s] for [%s], expected #rrggbb or an ANSI colour number", value, key)
+		}
+		*field = lipgloss.Color(value)
+	}
+	return t, nil
+}
+
+// FromConfig resolves the [theme] section of ttimelogrc. On error the
+// built-in theme is returned without the overrides.
+func FromConfig(appConfig *config.AppConfig, env Environment) (Theme, error) {
+	cfg := appConfig.Theme
+	t, err := Resolve(cfg.Name, env)
+	if err != nil {
+		return t, err
+	}
+	return t.WithOverrides(map[string]string{
+		"border_blur":    cfg.BorderBlur,
+		"border_focus":   cfg.BorderFocus,
+		"muted":          cfg.Muted,
+		"accent":         cfg.Accent,
+		"selected_fg":    cfg.SelectedFg,
+		"selected_bg":    cfg.SelectedBg,
+		"success":        cfg.Success,
+		"warning":        cfg.Warning,
+		"danger":         cfg.Danger,
+		"progress_start": cfg.ProgressStart,
+		"progress_end":   cfg.ProgressEnd,
+	})
+}
+
+var active = builtin[Dark]
+
+// Active returns the theme the TUI is rendered with
+func Active() Theme {
+	return active
+}
+
+// SetActive changes the theme used by Active
+func SetActive(t Theme) {
+	active = t
+}
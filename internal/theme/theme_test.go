@@ -0,0 +1,58 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	theme, err := Resolve("auto", Environment{HasDarkBackground: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Dark, theme.Name)
+
+	theme, err = Resolve("", Environment{HasDarkBackground: false})
+	assert.NoError(t, err)
+	assert.Equal(t, Light, theme.Name)
+
+	theme, err = Resolve("Solarized", Environment{HasDarkBackground: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Solarized, theme.Name)
+
+	// NO_COLOR wins over the configured theme
+	theme, err = Resolve(HighContrast, Environment{NoColor: true})
+	assert.NoError(t, err)
+	assert.True(t, theme.Monochrome)
+
+	_, err = Resolve("neon", Environment{})
+	assert.Error(t, err)
+}
+
+func TestWithOverrides(t *testing.T) {
+	theme, err := builtin[Dark].WithOverrides(map[string]string{
+		"border_focus": "#ff0000",
+		"muted":        "244",
+		"accent":       "",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, lipgloss.Color("#ff0000"), theme.BorderFocus)
+	assert.Equal(t, lipgloss.Color("244"), theme.Muted)
+	assert.Equal(t, builtin[Dark].Accent, theme.Accent)
+
+	_, err = builtin[Dark].WithOverrides(map[string]string{"accent": "blue"})
+	assert.Error(t, err)
+
+	_, err = builtin[Dark].WithOverrides(map[string]string{"background": "#000000"})
+	assert.Error(t, err)
+
+	// nothing is applied when one colour is wrong, the first one in sorted
+	// order is reported
+	theme, err = builtin[Dark].WithOverrides(map[string]string{
+		"border_focus": "#ff0000",
+		"muted":        "grey",
+		"accent":       "blue",
+	})
+	assert.ErrorContains(t, err, "[blue] for [accent]")
+	assert.Equal(t, builtin[Dark], theme)
+}
@@ -0,0 +1,116 @@
+package timelog
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout used to key entries by calendar day.
+const DateLayout = "2006-01-02"
+
+// IsSlackEntry reports whether the entry is slack time ("**" marker).
+func IsSlackEntry(entry Entry) bool {
+	return strings.Contains(entry.Description, "**")
+}
+
+// DateKey returns the calendar day an entry belongs to in DateLayout format.
+func DateKey(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// SummarizeDays sums work and slack per calendar day, keyed by DateKey.
+func SummarizeDays(entries []Entry) map[string]Stats {
+	days := make(map[string]Stats)
+	for _, entry := range entries {
+		key := DateKey(entry.EndTime)
+		stats := days[key]
+		if IsSlackEntry(entry) {
+			stats.Slack += entry.Duration
+		} else {
+			stats.Work += entry.Duration
+		}
+		days[key] = stats
+	}
+	return days
+}
+
+// Total is the time logged against a single project or category.
+type Total struct {
+	Name     string
+	Duration time.Duration
+}
+
+// NoProject is the name used for entries without a "project: " prefix.
+const NoProject = "(no project)"
+
+// ProjectOf returns the project part of a description, i.e. everything before
+// the first ": ". "A:B:C:D: Fix bug" belongs to "A:B:C:D".
+func ProjectOf(description string) string {
+	project, _, found := strings.Cut(description, ": ")
+	if !found || strings.TrimSpace(project) == "" {
+		return NoProject
+	}
+	return strings.TrimSpace(project)
+}
+
+// CategoryOf returns the top level of the entry's project path.
+func CategoryOf(description string) string {
+	category, _, _ := strings.Cut(ProjectOf(description), ":")
+	return category
+}
+
+// EntriesBetween returns the entries ending in [from, to).
+func EntriesBetween(entries []Entry, from, to time.Time) []Entry {
+	filtered := make([]Entry, 0)
+	for _, entry := range entries {
+		if entry.EndTime.Before(from) || !entry.EndTime.Before(to) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// SummarizeBy sums the work (not slack) of the entries grouped by key, largest first.
+func SummarizeBy(entries []Entry, key func(description string) string) []Total {
+	durations := make(map[string]time.Duration)
+	for _, entry := range entries {
+		if IsSlackEntry(entry) || entry.Duration == 0 {
+			continue
+		}
+		durations[key(entry.Description)] += entry.Duration
+	}
+
+	totals := make([]Total, 0, len(durations))
+	for name, duration := range durations {
+		totals = append(totals, Total{Name: name, Duration: duration})
+	}
+	slices.SortFunc(totals, func(a, b Total) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return totals
+}
+
+// StartOfDay returns the midnight starting the day of t.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// WeekRange returns the ISO week (Monday to Monday) containing t.
+func WeekRange(t time.Time) (time.Time, time.Time) {
+	day := StartOfDay(t)
+	monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return monday, monday.AddDate(0, 0, 7)
+}
+
+// MonthRange returns the calendar month containing t.
+func MonthRange(t time.Time) (time.Time, time.Time) {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return first, first.AddDate(0, 1, 0)
+}
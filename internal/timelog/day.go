@@ -0,0 +1,92 @@
+package timelog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const tailChunkSize = 64 * 1024
+
+// LoadDay returns the entries ending on the day of t. It reads the timelog
+// file backwards from its end and stops at the previous day, so it stays fast
+// however long the history is.
+func LoadDay(path string, t time.Time) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	day := DateKey(t)
+	var tail []byte
+	offset := info.Size()
+	for offset > 0 {
+		size := min(int64(tailChunkSize), offset)
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+		if offset > 0 && startsBefore(completeLines(tail), day) {
+			break
+		}
+	}
+	if offset > 0 {
+		tail = completeLines(tail)
+	}
+
+	entries := make([]Entry, 0)
+	var previous Entry
+	first := true
+	for _, line := range strings.Split(string(tail), "\n") {
+		line = strings.Trim(line, " ")
+		if line == "" {
+			continue
+		}
+		entry, err := parseEntry(line, first, previous)
+		if err != nil {
+			return nil, fmt.Errorf("line %q: %w", line, err)
+		}
+		first = false
+		previous = entry
+		if DateKey(entry.EndTime) == day {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// completeLines drops the first line of a chunk read from the middle of the
+// file, it may be cut
+func completeLines(tail []byte) []byte {
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		return tail[i+1:]
+	}
+	return nil
+}
+
+// startsBefore reports whether the first entry in lines ends before day, as
+// the file is in order all entries of day then follow
+func startsBefore(lines []byte, day string) bool {
+	for _, line := range strings.Split(string(lines), "\n") {
+		line = strings.Trim(line, " ")
+		if line == "" {
+			continue
+		}
+		date, _, _ := strings.Cut(line, " ")
+		return date < day
+	}
+	return false
+}
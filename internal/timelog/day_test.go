@@ -0,0 +1,73 @@
+package timelog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDay(t *testing.T) {
+	var history strings.Builder
+	// enough history to need several chunks
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day := start; day.Before(start.AddDate(0, 0, 400)); day = day.AddDate(0, 0, 1) {
+		fmt.Fprintf(&history, "\n%s 09:00 +0000: arrived**\n", day.Format(DateLayout))
+		for hour := 10; hour < 18; hour++ {
+			fmt.Fprintf(&history, "%s %02d:00 +0000: Acme:Web:Dev: work on a long running ticket\n", day.Format(DateLayout), hour)
+		}
+	}
+	history.WriteString("\n2025-03-10 09:00 +0000: arrived**\n" +
+		"2025-03-10 10:30 +0000: Acme:Web:Dev: fix bug\n" +
+		"2025-03-10 11:00 +0000: coffee **\n")
+
+	path := filepath.Join(t.TempDir(), "ttimelog.txt")
+	if err := os.WriteFile(path, []byte(history.String()), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	entries, err := LoadDay(path, time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, time.Duration(0), entries[0].Duration)
+		assert.Equal(t, 90*time.Minute, entries[1].Duration)
+		assert.Equal(t, 30*time.Minute, entries[2].Duration)
+	}
+
+	// a day from the middle is found as well
+	entries, err = LoadDay(path, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 9)
+
+	entries, err = LoadDay(path, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNewDayStatus(t *testing.T) {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{EndTime: day.Add(9 * time.Hour), Description: "arrived**"},
+		{EndTime: day.Add(10*time.Hour + 30*time.Minute), Description: "Acme:Web:Dev: fix bug", Duration: 90 * time.Minute},
+		{EndTime: day.Add(11 * time.Hour), Description: "coffee **", Duration: 30 * time.Minute},
+	}
+
+	status := NewDayStatus(entries, day.Add(11*time.Hour+20*time.Minute), 8*time.Hour)
+	assert.Equal(t, day.Add(9*time.Hour), status.Arrived)
+	assert.Equal(t, "coffee **", status.Last)
+	assert.Equal(t, 20*time.Minute, status.Elapsed)
+	assert.Equal(t, 90*time.Minute, status.Work)
+	assert.Equal(t, 30*time.Minute, status.Slack)
+	assert.Equal(t, 390*time.Minute, status.Remaining)
+	assert.Equal(t, day.Add(17*time.Hour), status.Leave)
+	assert.Equal(t, 18, status.Percent)
+
+	status = NewDayStatus(nil, day, 8*time.Hour)
+	assert.True(t, status.Arrived.IsZero())
+	assert.True(t, status.Leave.IsZero())
+	assert.Equal(t, 8*time.Hour, status.Remaining)
+}
@@ -19,6 +19,12 @@ type Entry struct {
 	Today        bool
 	CurrentWeek  bool
 	CurrentMonth bool
+
+	// Unsaved is set when writing the entry to the timelog file failed
+	Unsaved bool
+	// Line is the 1-based line of the entry in the timelog file, 0 if it
+	// wasn't loaded from it
+	Line int
 }
 
 type StatsCollection struct {
@@ -47,36 +53,31 @@ func NewEntry(endTime time.Time, description string, duration time.Duration) Ent
 	}
 }
 
-// TODO: Add test for SaveEntry
-// SaveEntry saves the entry in 'YYYY-MM-DD HH:MM +/-0000: Task Description' format
-func SaveEntry(entry Entry, addNewLine bool, timeLogFilePath string) error {
-	// Open the file in append mode. Create it if it doesn't exist.
-	// os.O_APPEND: Open the file for appending.
-	// os.O_WRONLY: Open the file for writing only.
-	// 0644: File permissions (read/write for owner, read-only for others).
-	f, err := os.OpenFile(timeLogFilePath, os.O_APPEND|os.O_WRONLY, 0o644)
-	if err != nil {
-		return err
+// LogEntry creates the entry for description logged at now after entries.
+// The first "arrived" message of the day starts the day: it has no duration
+// and startsDay tells to put a blank line before it in the file.
+func LogEntry(entries []Entry, handledArrived bool, description string, now time.Time) (entry Entry, startsDay bool) {
+	startsDay = IsArrivedMessage(description) && !handledArrived
+	lastTaskTime := now
+	if len(entries) > 0 && !startsDay {
+		lastTaskTime = entries[len(entries)-1].EndTime
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			slog.Error("Failed to close file", "error", err)
-		}
-	}()
-
-	dateAndTime := entry.EndTime.Format(timeLayout)
+	return NewEntry(now, description, now.Sub(lastTaskTime)), startsDay
+}
 
-	saveFormat := "%s: %s\n"
+// FormatEntry returns the entry as it is stored in the timelog file including
+// the line break, with a blank line before it when addNewLine is set.
+func FormatEntry(entry Entry, addNewLine bool) string {
+	text := FormatEntryLine(entry) + "\n"
 	if addNewLine {
-		saveFormat = "\n" + saveFormat
-	}
-	textEntry := fmt.Sprintf(saveFormat, dateAndTime, entry.Description)
-
-	if _, err := f.WriteString(textEntry); err != nil {
-		return err
+		text = "\n" + text
 	}
+	return text
+}
 
-	return nil
+// FormatEntryLine returns the entry in 'YYYY-MM-DD HH:MM +/-0000: Task Description' format
+func FormatEntryLine(entry Entry) string {
+	return entry.EndTime.Format(timeLayout) + ": " + entry.Description
 }
 
 func GetEntryState(t time.Time, now ...time.Time) (bool, bool, bool) {
@@ -163,7 +164,9 @@ func LoadEntries(filePath string) ([]Entry, StatsCollection, bool, error) {
 	}()
 
 	scanner := bufio.NewScanner(file)
+	lineNumber := 0
 	for scanner.Scan() {
+		lineNumber++
 		line := scanner.Text()
 		if line == "" {
 			continue
@@ -180,8 +183,9 @@ func LoadEntries(filePath string) ([]Entry, StatsCollection, bool, error) {
 		}
 
 		if err != nil {
-			return entries, statsCollection, handledArrivedMessage, err
+			return entries, statsCollection, handledArrivedMessage, fmt.Errorf("line %d: %w", lineNumber, err)
 		}
+		entry.Line = lineNumber
 
 		if entry.Today && IsArrivedMessage(entry.Description) {
 			handledArrivedMessage = true
@@ -199,7 +203,7 @@ func LoadEntries(filePath string) ([]Entry, StatsCollection, bool, error) {
 }
 
 func UpdateStatsCollection(entry Entry, statsCollection *StatsCollection) {
-	isSlackTime := strings.Contains(entry.Description, "**")
+	isSlackTime := IsSlackEntry(entry)
 	if entry.Today {
 		if isSlackTime {
 			statsCollection.Daily.Slack += entry.Duration
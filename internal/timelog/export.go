@@ -0,0 +1,97 @@
+package timelog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportCSV     = "csv"
+	ExportJSON    = "json"
+	ExportTimelog = "timelog"
+)
+
+// ExportFormats lists the formats of Export
+var ExportFormats = []string{ExportCSV, ExportJSON, ExportTimelog}
+
+// ExportedEntry is an entry with its start time and its description split
+// into project and task
+type ExportedEntry struct {
+	Date    string    `json:"date"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes int       `json:"minutes"`
+	Project string    `json:"project"`
+	Task    string    `json:"task"`
+	Slack   bool      `json:"slack"`
+}
+
+// ExportEntry converts an entry for Export and other consumers outside ttimelog
+func ExportEntry(entry Entry) ExportedEntry {
+	exported := ExportedEntry{
+		Date:    DateKey(entry.EndTime),
+		Start:   entry.EndTime.Add(-entry.Duration),
+		End:     entry.EndTime,
+		Minutes: int(entry.Duration.Minutes()),
+		Task:    entry.Description,
+		Slack:   IsSlackEntry(entry),
+	}
+	if project, task, found := strings.Cut(entry.Description, ": "); found && strings.TrimSpace(project) != "" {
+		exported.Project = strings.TrimSpace(project)
+		exported.Task = task
+	}
+	return exported
+}
+
+// Totals are minutes of work and slack, as served by the API and the dashboard
+type Totals struct {
+	Work  int `json:"work"`
+	Slack int `json:"slack"`
+}
+
+// ExportTotals converts stats for consumers outside ttimelog
+func ExportTotals(stats Stats) Totals {
+	return Totals{Work: int(stats.Work.Minutes()), Slack: int(stats.Slack.Minutes())}
+}
+
+// Export writes the entries in one of ExportFormats. The timelog format is
+// the one of ttimelog.txt.
+func Export(w io.Writer, format string, entries []Entry) error {
+	switch format {
+	case ExportCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"date", "start", "end", "minutes", "project", "task", "slack"}); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			e := ExportEntry(entry)
+			record := []string{e.Date, e.Start.Format("15:04"), e.End.Format("15:04"), strconv.Itoa(e.Minutes), e.Project, e.Task, strconv.FormatBool(e.Slack)}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case ExportJSON:
+		exported := make([]ExportedEntry, 0, len(entries))
+		for _, entry := range entries {
+			exported = append(exported, ExportEntry(entry))
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(exported)
+	case ExportTimelog:
+		for _, entry := range entries {
+			if _, err := io.WriteString(w, FormatEntryLine(entry)+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown export format[%s], use %s", format, strings.Join(ExportFormats, ", "))
+}
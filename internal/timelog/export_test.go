@@ -0,0 +1,71 @@
+package timelog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestEntries() []Entry {
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	return []Entry{
+		{EndTime: day.Add(9 * time.Hour), Description: "arrived**"},
+		{EndTime: day.Add(10*time.Hour + 30*time.Minute), Description: "Acme:Web: fix, then test", Duration: 90 * time.Minute},
+		{EndTime: day.Add(11 * time.Hour), Description: "coffee **", Duration: 30 * time.Minute},
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: ExportCSV,
+			expected: "date,start,end,minutes,project,task,slack\n" +
+				"2025-03-10,09:00,09:00,0,,arrived**,true\n" +
+				"2025-03-10,09:00,10:30,90,Acme:Web,\"fix, then test\",false\n" +
+				"2025-03-10,10:30,11:00,30,,coffee **,true\n",
+		},
+		{
+			format: ExportTimelog,
+			expected: "2025-03-10 09:00 +0000: arrived**\n" +
+				"2025-03-10 10:30 +0000: Acme:Web: fix, then test\n" +
+				"2025-03-10 11:00 +0000: coffee **\n",
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		assert.NoError(t, Export(&out, test.format, exportTestEntries()), test.format)
+		assert.Equal(t, test.expected, out.String(), test.format)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, Export(&out, ExportJSON, exportTestEntries()))
+	assert.Contains(t, out.String(), `"project": "Acme:Web"`)
+	assert.Contains(t, out.String(), `"minutes": 90`)
+
+	assert.Error(t, Export(&out, "xml", nil))
+}
+
+func TestLogEntry(t *testing.T) {
+	entries := exportTestEntries()
+	now := entries[2].EndTime.Add(time.Hour)
+
+	entry, startsDay := LogEntry(entries, true, "Acme:Web: review", now)
+	assert.False(t, startsDay)
+	assert.Equal(t, time.Hour, entry.Duration)
+
+	entry, startsDay = LogEntry(entries, false, "arrived**", now)
+	assert.True(t, startsDay)
+	assert.Equal(t, time.Duration(0), entry.Duration)
+
+	// only the first arrived message of the day starts it
+	_, startsDay = LogEntry(entries, true, "arrived**", now)
+	assert.False(t, startsDay)
+
+	entry, _ = LogEntry(nil, false, "first", now)
+	assert.Equal(t, time.Duration(0), entry.Duration)
+}
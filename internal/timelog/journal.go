@@ -0,0 +1,386 @@
+package timelog
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type OpKind int
+
+const (
+	OpAppend OpKind = iota
+	OpEdit
+	OpDelete
+	OpInsert
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpAppend:
+		return "append"
+	case OpEdit:
+		return "edit"
+	case OpDelete:
+		return "delete"
+	case OpInsert:
+		return "insert"
+	}
+	return "unknown"
+}
+
+// Operation is a single change to the timelog file.
+//
+// Append adds Text (which may start with blank lines) to the end of the file.
+// Edit replaces line Line, which must be Old, with Text. Delete removes line
+// Line, which must be Old. Insert puts Text before line Line.
+type Operation struct {
+	Kind OpKind
+	Line int
+	Old  string
+	Text string
+}
+
+var (
+	ErrNothingToUndo  = errors.New("nothing to undo")
+	ErrNothingToRedo  = errors.New("nothing to redo")
+	ErrExternalChange = errors.New("timelog file was changed outside ttimelog")
+)
+
+type checksum [sha256.Size]byte
+
+type journalItem struct {
+	op Operation
+	// size and sum of the file before and after the operation, to detect
+	// changes made by others in between. Appends have no sums, they check
+	// the size and their text at the end instead of reading the whole file
+	// twice for every logged entry.
+	sizeBefore int64
+	sizeAfter  int64
+	sumAfter   checksum
+	sumBefore  checksum
+}
+
+// Journal applies operations to the timelog file and keeps them for
+// multi-level undo and redo. Undo and redo refuse to touch the file when it
+// was modified since the journal last wrote it.
+type Journal struct {
+	path  string
+	limit int
+	undo  []journalItem
+	redo  []journalItem
+}
+
+const DefaultJournalLimit = 100
+
+// fileLocks serializes the writes of all journals of a file within the
+// process, e.g. of several SSH sessions
+var fileLocks sync.Map
+
+// lockFile locks path for writing and returns the unlock function
+func lockFile(path string) func() {
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+func NewJournal(path string, limit int) *Journal {
+	if limit <= 0 {
+		limit = DefaultJournalLimit
+	}
+	return &Journal{path: path, limit: limit}
+}
+
+// Reset forgets the history, e.g. after the whole file was rewritten
+func (j *Journal) Reset() {
+	j.undo = nil
+	j.redo = nil
+}
+
+func (j *Journal) CanUndo() bool {
+	return len(j.undo) > 0
+}
+
+func (j *Journal) CanRedo() bool {
+	return len(j.redo) > 0
+}
+
+// Apply performs op on the file and records it for undo, dropping any redo history
+func (j *Journal) Apply(op Operation) error {
+	defer lockFile(j.path)()
+
+	item, err := j.perform(op)
+	if err != nil {
+		return err
+	}
+	j.undo = append(j.undo, item)
+	if len(j.undo) > j.limit {
+		j.undo = j.undo[len(j.undo)-j.limit:]
+	}
+	j.redo = nil
+	return nil
+}
+
+// Undo reverts the last operation and returns it
+func (j *Journal) Undo() (Operation, error) {
+	defer lockFile(j.path)()
+
+	if len(j.undo) == 0 {
+		return Operation{}, ErrNothingToUndo
+	}
+	item := j.undo[len(j.undo)-1]
+	if err := j.verify(item.matchesAfter); err != nil {
+		return item.op, err
+	}
+
+	var err error
+	switch item.op.Kind {
+	case OpAppend:
+		err = os.Truncate(j.path, item.sizeBefore)
+	case OpEdit:
+		_, err = j.perform(Operation{Kind: OpEdit, Line: item.op.Line, Old: item.op.Text, Text: item.op.Old})
+	case OpDelete:
+		_, err = j.perform(Operation{Kind: OpInsert, Line: item.op.Line, Text: item.op.Old})
+	case OpInsert:
+		_, err = j.perform(Operation{Kind: OpDelete, Line: item.op.Line, Old: item.op.Text})
+	}
+	if err != nil {
+		return item.op, err
+	}
+
+	j.undo = j.undo[:len(j.undo)-1]
+	j.redo = append(j.redo, item)
+	return item.op, nil
+}
+
+// Redo applies the last undone operation again and returns it
+func (j *Journal) Redo() (Operation, error) {
+	defer lockFile(j.path)()
+
+	if len(j.redo) == 0 {
+		return Operation{}, ErrNothingToRedo
+	}
+	item := j.redo[len(j.redo)-1]
+	if err := j.verify(item.matchesBefore); err != nil {
+		return item.op, err
+	}
+
+	redone, err := j.perform(item.op)
+	if err != nil {
+		return item.op, err
+	}
+	j.redo = j.redo[:len(j.redo)-1]
+	j.undo = append(j.undo, redone)
+	return item.op, nil
+}
+
+func fileSum(path string) (checksum, int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return checksum{}, 0, err
+	}
+	return sha256.Sum256(content), int64(len(content)), nil
+}
+
+// verify checks the file with matches, the history is dropped when it was
+// changed by others
+func (j *Journal) verify(matches func(path string) (bool, error)) error {
+	ok, err := matches(j.path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// history before an external change can't be trusted anymore
+		j.undo = nil
+		j.redo = nil
+		return ErrExternalChange
+	}
+	return nil
+}
+
+// matchesAfter tells whether the file is as the operation left it
+func (item journalItem) matchesAfter(path string) (bool, error) {
+	if item.op.Kind != OpAppend {
+		sum, _, err := fileSum(path)
+		return sum == item.sumAfter, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != item.sizeAfter {
+		return false, nil
+	}
+	tail := make([]byte, item.sizeAfter-item.sizeBefore)
+	if _, err := f.ReadAt(tail, item.sizeBefore); err != nil {
+		return false, err
+	}
+	return string(tail) == item.op.Text, nil
+}
+
+// matchesBefore tells whether the file is as it was before the operation
+func (item journalItem) matchesBefore(path string) (bool, error) {
+	if item.op.Kind != OpAppend {
+		sum, _, err := fileSum(path)
+		return sum == item.sumBefore, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return info.Size() == item.sizeBefore, nil
+}
+
+func (j *Journal) perform(op Operation) (journalItem, error) {
+	if op.Kind == OpAppend {
+		info, err := os.Stat(j.path)
+		if err != nil {
+			return journalItem{}, err
+		}
+		if err := appendText(j.path, op.Text); err != nil {
+			return journalItem{}, err
+		}
+		size := info.Size()
+		return journalItem{op: op, sizeBefore: size, sizeAfter: size + int64(len(op.Text))}, nil
+	}
+
+	sumBefore, sizeBefore, err := fileSum(j.path)
+	if err != nil {
+		return journalItem{}, err
+	}
+
+	switch op.Kind {
+	case OpEdit, OpDelete, OpInsert:
+		err = rewriteLine(j.path, op)
+	default:
+		err = fmt.Errorf("unknown operation[%d]", op.Kind)
+	}
+	if err != nil {
+		return journalItem{}, err
+	}
+
+	sumAfter, sizeAfter, err := fileSum(j.path)
+	if err != nil {
+		return journalItem{}, err
+	}
+	return journalItem{op: op, sizeBefore: sizeBefore, sizeAfter: sizeAfter, sumBefore: sumBefore, sumAfter: sumAfter}, nil
+}
+
+func appendText(path, text string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func rewriteLine(path string, op Operation) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	// a trailing newline leaves an empty last element which is not a line
+	trailingNewline := strings.HasSuffix(string(content), "\n")
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+
+	index := op.Line - 1
+	switch op.Kind {
+	case OpEdit, OpDelete:
+		if index < 0 || index >= len(lines) || lines[index] != op.Old {
+			return fmt.Errorf("line %d is not %q: %w", op.Line, op.Old, ErrExternalChange)
+		}
+		if op.Kind == OpEdit {
+			lines[index] = op.Text
+		} else {
+			lines = append(lines[:index], lines[index+1:]...)
+		}
+	case OpInsert:
+		if index < 0 || index > len(lines) {
+			return fmt.Errorf("can't insert at line %d of %d: %w", op.Line, len(lines), ErrExternalChange)
+		}
+		lines = append(lines[:index], append([]string{op.Text}, lines[index:]...)...)
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return WriteFileAtomic(path, []byte(result))
+}
+
+// WriteFileAtomic replaces the file by writing a temporary file next to it
+// and renaming it over, keeping the permissions of the original.
+func WriteFileAtomic(path string, content []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op once renamed
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// EntryLine returns the text of the line entry was loaded from, failing with
+// ErrExternalChange when that line no longer holds the entry.
+func EntryLine(path string, entry Entry) (string, error) {
+	if entry.Line <= 0 {
+		return "", fmt.Errorf("entry %q is not in the timelog file yet", entry.Description)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(content), "\n")
+	if entry.Line > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of the file: %w", entry.Line, ErrExternalChange)
+	}
+
+	line := lines[entry.Line-1]
+	parsed, err := parseEntry(strings.Trim(line, " "), true, Entry{})
+	if err != nil || !parsed.EndTime.Equal(entry.EndTime) || parsed.Description != entry.Description {
+		return "", fmt.Errorf("line %d no longer holds %q: %w", entry.Line, entry.Description, ErrExternalChange)
+	}
+	return line, nil
+}
@@ -0,0 +1,125 @@
+package timelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const journalBase = "2025-03-10 09:00 +0000: arrived**\n2025-03-10 10:00 +0000: Project: one\n2025-03-10 11:00 +0000: Project: two\n"
+
+func newTestJournal(t *testing.T) (*Journal, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ttimelog.txt")
+	if err := os.WriteFile(path, []byte(journalBase), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+	return NewJournal(path, 0), path
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(content)
+}
+
+func TestJournalUndoRedo(t *testing.T) {
+	journal, path := newTestJournal(t)
+
+	ops := []struct {
+		op   Operation
+		want string
+	}{
+		{
+			Operation{Kind: OpAppend, Text: "\n2025-03-11 09:00 +0000: arrived**\n"},
+			journalBase + "\n2025-03-11 09:00 +0000: arrived**\n",
+		},
+		{
+			Operation{Kind: OpEdit, Line: 2, Old: "2025-03-10 10:00 +0000: Project: one", Text: "2025-03-10 10:00 +0000: Project: uno"},
+			"2025-03-10 09:00 +0000: arrived**\n2025-03-10 10:00 +0000: Project: uno\n2025-03-10 11:00 +0000: Project: two\n\n2025-03-11 09:00 +0000: arrived**\n",
+		},
+		{
+			Operation{Kind: OpDelete, Line: 3, Old: "2025-03-10 11:00 +0000: Project: two"},
+			"2025-03-10 09:00 +0000: arrived**\n2025-03-10 10:00 +0000: Project: uno\n\n2025-03-11 09:00 +0000: arrived**\n",
+		},
+		{
+			Operation{Kind: OpInsert, Line: 2, Text: "2025-03-10 09:30 +0000: Project: zero"},
+			"2025-03-10 09:00 +0000: arrived**\n2025-03-10 09:30 +0000: Project: zero\n2025-03-10 10:00 +0000: Project: uno\n\n2025-03-11 09:00 +0000: arrived**\n",
+		},
+	}
+
+	states := []string{journalBase}
+	for _, step := range ops {
+		assert.NoError(t, journal.Apply(step.op), step.op.Kind.String())
+		assert.Equal(t, step.want, readTestFile(t, path), step.op.Kind.String())
+		states = append(states, step.want)
+	}
+
+	// undo everything, one level at a time
+	for i := len(ops) - 1; i >= 0; i-- {
+		op, err := journal.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, ops[i].op, op)
+		assert.Equal(t, states[i], readTestFile(t, path))
+	}
+	_, err := journal.Undo()
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	for i := range ops {
+		_, err := journal.Redo()
+		assert.NoError(t, err)
+		assert.Equal(t, states[i+1], readTestFile(t, path))
+	}
+	_, err = journal.Redo()
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+}
+
+func TestJournalExternalChange(t *testing.T) {
+	journal, path := newTestJournal(t)
+	assert.NoError(t, journal.Apply(Operation{Kind: OpAppend, Text: "2025-03-10 12:00 +0000: Project: mine\n"}))
+
+	// someone else logs a line after ours
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString("2025-03-10 13:00 +0000: Project: theirs\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	before := readTestFile(t, path)
+	_, err = journal.Undo()
+	assert.ErrorIs(t, err, ErrExternalChange)
+	assert.Equal(t, before, readTestFile(t, path))
+	assert.False(t, journal.CanUndo())
+
+	// edits check the line they replace
+	err = journal.Apply(Operation{Kind: OpEdit, Line: 2, Old: "not this line", Text: "x"})
+	assert.ErrorIs(t, err, ErrExternalChange)
+}
+
+func TestJournalAppendChecksItsText(t *testing.T) {
+	journal, path := newTestJournal(t)
+	mine := "2025-03-10 12:00 +0000: Project: mine\n"
+	assert.NoError(t, journal.Apply(Operation{Kind: OpAppend, Text: mine}))
+
+	// our line was changed in place, the size stays the same
+	changed := journalBase + "2025-03-10 12:00 +0000: Project: ours\n"
+	assert.NoError(t, os.WriteFile(path, []byte(changed), 0o644))
+	_, err := journal.Undo()
+	assert.ErrorIs(t, err, ErrExternalChange)
+	assert.Equal(t, changed, readTestFile(t, path))
+
+	// a redo needs the file to be as long as before the append
+	assert.NoError(t, os.WriteFile(path, []byte(journalBase), 0o644))
+	assert.NoError(t, journal.Apply(Operation{Kind: OpAppend, Text: mine}))
+	_, err = journal.Undo()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, []byte(journalBase+"\n"), 0o644))
+	_, err = journal.Redo()
+	assert.ErrorIs(t, err, ErrExternalChange)
+	assert.Equal(t, journalBase+"\n", readTestFile(t, path))
+}
@@ -0,0 +1,169 @@
+package timelog
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PathMapping renames the project path prefix From to To
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// Remapping is a set of project path renames, the longest matching prefix
+// wins
+type Remapping []PathMapping
+
+func normalizePath(path string) string {
+	levels := make([]string, 0)
+	for _, level := range strings.Split(path, ":") {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return strings.Join(levels, ":")
+}
+
+// ParseRemapping reads one "Old:Path = New:Path" rename per line, "->" works
+// as separator as well. Lines starting with # are comments.
+func ParseRemapping(r io.Reader) (Remapping, error) {
+	remapping := make(Remapping, 0)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		from, to, found := strings.Cut(line, "->")
+		if !found {
+			from, to, found = strings.Cut(line, "=")
+		}
+		mapping := PathMapping{From: normalizePath(from), To: normalizePath(to)}
+		if !found || mapping.From == "" || mapping.To == "" {
+			return nil, fmt.Errorf("line %d: expected \"Old:Path = New:Path\", got %q", lineNumber, line)
+		}
+		remapping = append(remapping, mapping)
+	}
+	return remapping, scanner.Err()
+}
+
+// LoadRemapping reads a mapping file, see ParseRemapping
+func LoadRemapping(path string) (Remapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return ParseRemapping(f)
+}
+
+// Project returns the renamed project path. Prefixes only match whole
+// levels, "A:B" renames "A:B:C" but not "A:BC".
+func (r Remapping) Project(project string) (string, bool) {
+	project = normalizePath(project)
+	best := -1
+	for i, mapping := range r {
+		if project != mapping.From && !strings.HasPrefix(project, mapping.From+":") {
+			continue
+		}
+		if best < 0 || len(mapping.From) > len(r[best].From) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return project, false
+	}
+	renamed := r[best].To + strings.TrimPrefix(project, r[best].From)
+	return renamed, renamed != project
+}
+
+// LineChange is one rewritten line of the timelog file
+type LineChange struct {
+	Line    int
+	EndTime time.Time
+	Old     string
+	New     string
+}
+
+// PlanRemap returns the lines of the timelog file whose entries end in
+// [from, to) and have a project renamed by remapping. A zero from or to
+// leaves that end of the range open.
+func PlanRemap(path string, remapping Remapping, from, to time.Time) ([]LineChange, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]LineChange, 0)
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, err := parseEntry(strings.Trim(line, " "), true, Entry{})
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if (!from.IsZero() && entry.EndTime.Before(from)) || (!to.IsZero() && !entry.EndTime.Before(to)) {
+			continue
+		}
+
+		project, description, found := strings.Cut(entry.Description, ": ")
+		if !found || strings.TrimSpace(project) == "" {
+			continue
+		}
+		renamed, ok := remapping.Project(project)
+		if !ok {
+			continue
+		}
+		entry.Description = renamed + ": " + description
+		changes = append(changes, LineChange{Line: i + 1, EndTime: entry.EndTime, Old: line, New: FormatEntryLine(entry)})
+	}
+	return changes, nil
+}
+
+// BackupPath is where ApplyRemap copies the timelog file before rewriting it
+func BackupPath(path string, now time.Time) string {
+	return path + ".bak-" + now.Format("20060102-150405")
+}
+
+// ApplyRemap writes a backup of the timelog file to BackupPath and then
+// rewrites the changed lines atomically. Nothing is written when a line no
+// longer matches its planned Old text.
+func ApplyRemap(path string, changes []LineChange, now time.Time) (string, error) {
+	defer lockFile(path)()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	changes = slices.SortedFunc(slices.Values(changes), func(a, b LineChange) int { return cmp.Compare(a.Line, b.Line) })
+	for _, change := range changes {
+		index := change.Line - 1
+		if index < 0 || index >= len(lines) || lines[index] != change.Old {
+			return "", fmt.Errorf("line %d is not %q: %w", change.Line, change.Old, ErrExternalChange)
+		}
+		lines[index] = change.New
+	}
+
+	backupPath := BackupPath(path, now)
+	if err := WriteFileAtomic(backupPath, content); err != nil {
+		return "", fmt.Errorf("failed to write backup[%s] with error[%v]", backupPath, err)
+	}
+	if err := WriteFileAtomic(path, []byte(strings.Join(lines, "\n"))); err != nil {
+		return backupPath, err
+	}
+	return backupPath, nil
+}
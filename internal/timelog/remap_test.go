@@ -0,0 +1,93 @@
+package timelog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemapping(t *testing.T) {
+	remapping, err := ParseRemapping(strings.NewReader(`# renames of the spring reorganisation
+Acme:Web = Acme:Sites:Web
+Acme : Web : Legacy -> Acme:Archive
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, Remapping{
+		{From: "Acme:Web", To: "Acme:Sites:Web"},
+		{From: "Acme:Web:Legacy", To: "Acme:Archive"},
+	}, remapping)
+
+	_, err = ParseRemapping(strings.NewReader("Acme:Web\n"))
+	assert.Error(t, err)
+}
+
+func TestRemappingProject(t *testing.T) {
+	remapping := Remapping{
+		{From: "Acme:Web", To: "Acme:Sites:Web"},
+		{From: "Acme:Web:Legacy", To: "Acme:Archive"},
+	}
+
+	tests := []struct {
+		project  string
+		expected string
+		renamed  bool
+	}{
+		{"Acme:Web", "Acme:Sites:Web", true},
+		{"Acme:Web:Dev", "Acme:Sites:Web:Dev", true},
+		{"Acme:Web:Legacy:Fixes", "Acme:Archive:Fixes", true},
+		{"Acme:Webshop:Dev", "Acme:Webshop:Dev", false},
+		{"Internal:Meeting", "Internal:Meeting", false},
+	}
+	for _, test := range tests {
+		renamed, ok := remapping.Project(test.project)
+		assert.Equal(t, test.expected, renamed, test.project)
+		assert.Equal(t, test.renamed, ok, test.project)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	const content = "2025-03-09 17:00 +0000: Acme:Web:Dev: before\n\n" +
+		"2025-03-10 09:00 +0000: arrived**\n" +
+		"2025-03-10 10:00 +0000: Acme:Web:Dev: fix bug\n" +
+		"2025-03-10 11:00 +0000: Internal:Meeting: standup\n" +
+		"2025-03-10 12:00 +0000: lunch **\n"
+	path := filepath.Join(t.TempDir(), "ttimelog.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	remapping := Remapping{{From: "Acme:Web", To: "Acme:Sites:Web"}}
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	changes, err := PlanRemap(path, remapping, from, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, 4, changes[0].Line)
+		assert.True(t, changes[0].EndTime.Equal(time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC)))
+		assert.Equal(t, "2025-03-10 10:00 +0000: Acme:Web:Dev: fix bug", changes[0].Old)
+		assert.Equal(t, "2025-03-10 10:00 +0000: Acme:Sites:Web:Dev: fix bug", changes[0].New)
+	}
+
+	// the whole history
+	all, err := PlanRemap(path, remapping, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	now := time.Date(2025, 3, 11, 8, 30, 0, 0, time.UTC)
+	backupPath, err := ApplyRemap(path, changes, now)
+	assert.NoError(t, err)
+	assert.Equal(t, path+".bak-20250311-083000", backupPath)
+	assert.Equal(t, content, readTestFile(t, backupPath))
+	assert.Equal(t, strings.Replace(content, "Acme:Web:Dev: fix bug", "Acme:Sites:Web:Dev: fix bug", 1), readTestFile(t, path))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// applying the same plan again finds the lines changed
+	_, err = ApplyRemap(path, changes, now.Add(time.Minute))
+	assert.ErrorIs(t, err, ErrExternalChange)
+}
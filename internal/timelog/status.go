@@ -0,0 +1,54 @@
+package timelog
+
+import "time"
+
+// DayStatus summarizes the day so far, e.g. for status bars
+type DayStatus struct {
+	Now time.Time
+	// Arrived is zero until the day was started with an arrived message
+	Arrived time.Time
+	// Last is the description of the last entry of the day, LastAt its time
+	Last   string
+	LastAt time.Time
+	// Elapsed is the time since the last entry, spent on the current task
+	Elapsed   time.Duration
+	Work      time.Duration
+	Slack     time.Duration
+	Target    time.Duration
+	Remaining time.Duration
+	// Leave is when the target is reached counting from Arrived, zero when
+	// not arrived
+	Leave time.Time
+	// Percent is the work done of the target
+	Percent int
+}
+
+// NewDayStatus computes the status from the entries of the day, see LoadDay
+func NewDayStatus(entries []Entry, now time.Time, target time.Duration) DayStatus {
+	status := DayStatus{Now: now, Target: target}
+	for _, entry := range entries {
+		if IsArrivedMessage(entry.Description) && status.Arrived.IsZero() {
+			status.Arrived = entry.EndTime
+		}
+		if IsSlackEntry(entry) {
+			status.Slack += entry.Duration
+		} else {
+			status.Work += entry.Duration
+		}
+	}
+
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		status.Last = last.Description
+		status.LastAt = last.EndTime
+		status.Elapsed = max(now.Sub(last.EndTime), 0)
+	}
+	status.Remaining = max(target-status.Work, 0)
+	if !status.Arrived.IsZero() {
+		status.Leave = status.Arrived.Add(target)
+	}
+	if target > 0 {
+		status.Percent = int(100 * status.Work / target)
+	}
+	return status
+}
@@ -3,6 +3,7 @@ package timelog
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -89,3 +90,61 @@ func TestLoadEntries(t *testing.T) {
 	// Entry 3 (Today 10:00) -> Duration 1h
 	assert.Equal(t, 1*time.Hour, entries[3].Duration)
 }
+
+func TestSummarizeDays(t *testing.T) {
+	day := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		NewEntry(day, "arrived**", 0),
+		NewEntry(day.Add(2*time.Hour), "Project: coding", 2*time.Hour),
+		NewEntry(day.Add(3*time.Hour), "lunch **", time.Hour),
+		NewEntry(day.AddDate(0, 0, 1), "Project: review", 30*time.Minute),
+	}
+
+	days := SummarizeDays(entries)
+	assert.Len(t, days, 2)
+	assert.Equal(t, Stats{Work: 2 * time.Hour, Slack: time.Hour}, days["2025-03-10"])
+	assert.Equal(t, Stats{Work: 30 * time.Minute}, days["2025-03-11"])
+}
+
+func TestSummarizeBy(t *testing.T) {
+	day := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		NewEntry(day, "arrived**", 0),
+		NewEntry(day.Add(time.Hour), "Collabora:Office:Core:Dev: fix crash", time.Hour),
+		NewEntry(day.Add(2*time.Hour), "lunch **", time.Hour),
+		NewEntry(day.Add(4*time.Hour), "Collabora:Office:Core:Dev: review", 2*time.Hour),
+		NewEntry(day.Add(5*time.Hour), "Collabora:Online:Web:QA: testing", time.Hour),
+		NewEntry(day.Add(6*time.Hour), "reading mail", time.Hour),
+	}
+
+	assert.Equal(t, []Total{
+		{Name: "Collabora:Office:Core:Dev", Duration: 3 * time.Hour},
+		{Name: NoProject, Duration: time.Hour},
+		{Name: "Collabora:Online:Web:QA", Duration: time.Hour},
+	}, SummarizeBy(entries, ProjectOf))
+
+	assert.Equal(t, []Total{
+		{Name: "Collabora", Duration: 4 * time.Hour},
+		{Name: NoProject, Duration: time.Hour},
+	}, SummarizeBy(entries, CategoryOf))
+
+	from, to := WeekRange(day)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC), to)
+	assert.Len(t, EntriesBetween(entries, day.Add(time.Hour), day.Add(5*time.Hour)), 3)
+}
+
+func TestLoadEntriesLineNumbers(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "ttimelog.txt")
+	content := "2025-03-10 09:00 +0000: arrived**\n2025-03-10 10:00 +0000: task\n\n2025-03-11 09:00 +0000: arrived**\nnot an entry\n"
+	if err := os.WriteFile(tmpFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	entries, _, _, err := LoadEntries(tmpFile)
+	assert.ErrorContains(t, err, "line 5")
+	assert.Len(t, entries, 3)
+	assert.Equal(t, 1, entries[0].Line)
+	assert.Equal(t, 2, entries[1].Line)
+	assert.Equal(t, 4, entries[2].Line)
+}
@@ -7,6 +7,10 @@ type TreeNode struct {
 	Children []*TreeNode
 	Expanded bool
 	Path     string
+	// Code, Description and Inactive come from project lists that have them
+	Code        string
+	Description string
+	Inactive    bool
 }
 
 type Row struct {
@@ -33,24 +37,38 @@ func Traverse(node *TreeNode, depth int, rows *[]Row) {
 	}
 }
 
-func AppendPath(rootNode *TreeNode, path []string, index int) {
+// AppendPath adds the nodes of path below rootNode that don't exist yet and
+// returns the node of the last label
+func AppendPath(rootNode *TreeNode, path []string, index int) *TreeNode {
 	// Base case: no more labels to consume
 	if len(path) == index {
-		return
+		return rootNode
 	}
 
 	currentLabel := path[index]
 
 	for _, child := range rootNode.Children {
 		if child.Label == currentLabel {
-			AppendPath(child, path, index+1)
-			return
+			return AppendPath(child, path, index+1)
 		}
 	}
 
-	// TODO: improve this to only save "Path" for leaf nodes
-	newChild := &TreeNode{Label: currentLabel, Path: strings.Join(path, ":")}
+	newChild := &TreeNode{Label: currentLabel, Path: strings.Join(path[:index+1], ":")}
 	rootNode.Children = append(rootNode.Children, newChild)
 
-	AppendPath(newChild, path, index+1)
+	return AppendPath(newChild, path, index+1)
+}
+
+// Clone copies the tree below node, so views of the same tree can expand
+// their nodes independently
+func (node *TreeNode) Clone() *TreeNode {
+	if node == nil {
+		return nil
+	}
+	clone := *node
+	clone.Children = make([]*TreeNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		clone.Children = append(clone.Children, child.Clone())
+	}
+	return &clone
 }
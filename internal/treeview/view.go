@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
 )
 
 type TreeView struct {
@@ -11,6 +12,21 @@ type TreeView struct {
 	Rows     []Row
 	Cursor   int
 	Viewport viewport.Model
+	Styles   Styles
+}
+
+// Styles of the rows, inactive projects are Muted and faint
+type Styles struct {
+	Selected lipgloss.Style
+	// Muted is used for codes and inactive projects
+	Muted lipgloss.Style
+}
+
+func DefaultStyles() Styles {
+	return Styles{
+		Selected: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+		Muted:    lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	}
 }
 
 func NewTreeView(root *TreeNode) *TreeView {
@@ -21,6 +37,7 @@ func NewTreeView(root *TreeNode) *TreeView {
 		Rows:     rows,
 		Viewport: viewport.New(10, 20),
 		Cursor:   0,
+		Styles:   DefaultStyles(),
 	}
 }
 
@@ -69,10 +86,22 @@ func (t *TreeView) Toggle() {
 func (t *TreeView) View() string {
 	var b strings.Builder
 
+	selectedStyle := t.Styles.Selected
+	mutedStyle := t.Styles.Muted
+	inactiveStyle := mutedStyle.Faint(true)
+
 	for i, row := range t.Rows {
 		cursor := " "
-		if i == t.Cursor {
+		label := row.TreeNode.Label
+		switch {
+		case i == t.Cursor:
 			cursor = ">"
+			label = selectedStyle.Render(label)
+		case row.TreeNode.Inactive:
+			label = inactiveStyle.Render(label)
+		}
+		if row.TreeNode.Code != "" {
+			label += " " + mutedStyle.Render("["+row.TreeNode.Code+"]")
 		}
 
 		indent := strings.Repeat("  ", row.Depth)
@@ -91,7 +120,7 @@ func (t *TreeView) View() string {
 		b.WriteString(indent)
 		b.WriteString(icon)
 		b.WriteString(" ")
-		b.WriteString(row.TreeNode.Label)
+		b.WriteString(label)
 		b.WriteString("\n")
 	}
 
@@ -111,3 +140,11 @@ func (t *TreeView) GetProjectPath() string {
 	}
 	return node.Path + ": "
 }
+
+// SelectedNode returns the node under the cursor, nil for an empty tree
+func (t *TreeView) SelectedNode() *TreeNode {
+	if t.Cursor < 0 || t.Cursor >= len(t.Rows) {
+		return nil
+	}
+	return t.Rows[t.Cursor].TreeNode
+}
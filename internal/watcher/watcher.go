@@ -0,0 +1,192 @@
+// Package watcher reports changes of a single file, coping with the
+// different ways editors save files
+package watcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	DefaultDebounce     = 150 * time.Millisecond
+	DefaultPollInterval = 2 * time.Second
+)
+
+type Options struct {
+	// Debounce is how long events have to settle before a change is reported
+	Debounce time.Duration
+	// PollInterval is used when inotify/kqueue can't be used
+	PollInterval time.Duration
+	// ForcePolling skips fsnotify, mainly for tests
+	ForcePolling bool
+
+	// OnChange is called once per burst of changes to the file
+	OnChange func()
+	// OnError is called for errors that don't stop the watcher
+	OnError func(error)
+	// OnPolling is called when falling back to polling, with the reason
+	OnPolling func(error)
+}
+
+// fileState identifies a version of the file, nil when it doesn't exist
+type fileState struct {
+	info os.FileInfo
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{info: info}
+}
+
+func (s fileState) equal(other fileState) bool {
+	if s.info == nil || other.info == nil {
+		return s.info == nil && other.info == nil
+	}
+	return os.SameFile(s.info, other.info) &&
+		s.info.ModTime().Equal(other.info.ModTime()) &&
+		s.info.Size() == other.info.Size()
+}
+
+// Watch blocks until ctx is done, calling opts.OnChange when the file at
+// path was modified, replaced by a rename or deleted and created again.
+//
+// The parent directory is watched rather than the file, so the watch
+// survives editors replacing the file. Events are debounced and a change is
+// only reported when the file differs from the last reported version. If
+// no fsnotify watch can be set up (e.g. inotify watches ran out) the file
+// is polled instead.
+func Watch(ctx context.Context, path string, opts Options) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.OnChange == nil {
+		opts.OnChange = func() {}
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(error) {}
+	}
+
+	if opts.ForcePolling {
+		return poll(ctx, path, opts)
+	}
+
+	watcher, err := newDirWatcher(filepath.Dir(path))
+	if err != nil {
+		slog.Warn("Falling back to polling for file changes", "filePath", path, "error", err)
+		if opts.OnPolling != nil {
+			opts.OnPolling(err)
+		}
+		return poll(ctx, path, opts)
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			slog.Error("Failed to close watcher", "error", err.Error())
+		}
+	}()
+
+	return watchEvents(ctx, watcher, path, opts)
+}
+
+func newDirWatcher(dir string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(dir); err != nil {
+		if closeErr := watcher.Close(); closeErr != nil {
+			slog.Error("Failed to close watcher", "error", closeErr.Error())
+		}
+		return nil, err
+	}
+	return watcher, nil
+}
+
+const relevantOps = fsnotify.Write | fsnotify.Create | fsnotify.Rename | fsnotify.Remove
+
+func watchEvents(ctx context.Context, watcher *fsnotify.Watcher, path string, opts Options) error {
+	name := filepath.Base(path)
+	last := statFile(path)
+
+	// nil channel until the first event of a burst arrives
+	var settle <-chan time.Time
+	timer := time.NewTimer(opts.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op&relevantOps == 0 || filepath.Base(event.Name) != name {
+				continue
+			}
+			// restart the debounce period on every event of the burst
+			timer.Reset(opts.Debounce)
+			settle = timer.C
+		case <-settle:
+			settle = nil
+			last = reportIfChanged(path, last, opts)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// events were lost, check the file ourselves
+				timer.Reset(opts.Debounce)
+				settle = timer.C
+				continue
+			}
+			opts.OnError(err)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func poll(ctx context.Context, path string, opts Options) error {
+	last := statFile(path)
+	seen := last
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			current := statFile(path)
+			if !current.equal(seen) {
+				// still being written, wait for it to settle for one interval
+				seen = current
+				continue
+			}
+			last = reportIfChanged(path, last, opts)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reportIfChanged calls OnChange when the file exists and differs from last.
+// A missing file is not reported, as editors deleting and re-creating it will
+// trigger another event once the new file is in place.
+func reportIfChanged(path string, last fileState, opts Options) fileState {
+	current := statFile(path)
+	if current.info == nil || current.equal(last) {
+		return current
+	}
+	opts.OnChange()
+	return current
+}
@@ -0,0 +1,186 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDebounce = 50 * time.Millisecond
+	// time given to the watcher to report after the editor saved
+	testSettle = 400 * time.Millisecond
+)
+
+// startWatcher watches ttimelog.txt in a temp dir and returns the path and a
+// counter of reported changes
+func startWatcher(t *testing.T, forcePolling bool) (string, *atomic.Int32) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ttimelog.txt")
+	if err := os.WriteFile(path, []byte("2025-01-01 09:00 +0000: arrived**\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create timelog: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := &atomic.Int32{}
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := Watch(ctx, path, Options{
+			Debounce:     testDebounce,
+			PollInterval: 20 * time.Millisecond,
+			ForcePolling: forcePolling,
+			OnChange:     func() { changes.Add(1) },
+			OnError:      func(err error) { t.Errorf("Unexpected watcher error: %v", err) },
+		})
+		assert.NoError(t, err)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// let the watcher register before the editor starts writing
+	time.Sleep(testDebounce)
+	return path, changes
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to open timelog: %v", err)
+	}
+	if _, err := f.WriteString(line); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read timelog: %v", err)
+	}
+	return content
+}
+
+const newLine = "2025-01-01 10:00 +0000: Project: task\n"
+
+// editor save strategies, each is expected to produce exactly one change
+var saveStrategies = map[string]func(t *testing.T, path string){
+	// nano, VS Code: truncate and write in place
+	"in place": func(t *testing.T, path string) {
+		content := append(readFile(t, path), newLine...)
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	},
+	// ttimelog itself: several appends in a burst
+	"append burst": func(t *testing.T, path string) {
+		for range 5 {
+			appendLine(t, path, newLine)
+		}
+	},
+	// vim with backupcopy=no, emacs, IntelliJ: write a temp file and rename it over
+	"rename over": func(t *testing.T, path string) {
+		tmp := path + ".tmp"
+		content := append(readFile(t, path), newLine...)
+		if err := os.WriteFile(tmp, content, 0o644); err != nil {
+			t.Fatalf("Failed to write temp file: %v", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatalf("Failed to rename: %v", err)
+		}
+	},
+	// vim writebackup: move the original away, write a new file, drop the backup
+	"backup rename": func(t *testing.T, path string) {
+		backup := path + "~"
+		content := append(readFile(t, path), newLine...)
+		if err := os.Rename(path, backup); err != nil {
+			t.Fatalf("Failed to rename to backup: %v", err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if err := os.Remove(backup); err != nil {
+			t.Fatalf("Failed to remove backup: %v", err)
+		}
+	},
+	// some sync tools: delete, then create the new version
+	"delete then create": func(t *testing.T, path string) {
+		content := append(readFile(t, path), newLine...)
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("Failed to remove: %v", err)
+		}
+		time.Sleep(testDebounce / 5)
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	},
+}
+
+func TestEditorSaveStrategies(t *testing.T) {
+	for _, polling := range []bool{false, true} {
+		for name, save := range saveStrategies {
+			mode := "fsnotify"
+			if polling {
+				mode = "polling"
+			}
+			t.Run(mode+"/"+name, func(t *testing.T) {
+				t.Parallel()
+				path, changes := startWatcher(t, polling)
+
+				save(t, path)
+				time.Sleep(testSettle)
+
+				assert.Equal(t, int32(1), changes.Load())
+			})
+		}
+	}
+}
+
+func TestIgnoresOtherFiles(t *testing.T) {
+	t.Parallel()
+	path, changes := startWatcher(t, false)
+
+	// vim probes the directory with a "4913" file before saving
+	probe := filepath.Join(filepath.Dir(path), "4913")
+	if err := os.WriteFile(probe, []byte{}, 0o644); err != nil {
+		t.Fatalf("Failed to write probe: %v", err)
+	}
+	if err := os.Remove(probe); err != nil {
+		t.Fatalf("Failed to remove probe: %v", err)
+	}
+	time.Sleep(testSettle)
+
+	assert.Equal(t, int32(0), changes.Load())
+}
+
+func TestDeleteIsNotReported(t *testing.T) {
+	t.Parallel()
+	path, changes := startWatcher(t, false)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	time.Sleep(testSettle)
+	assert.Equal(t, int32(0), changes.Load())
+
+	if err := os.WriteFile(path, []byte(newLine), 0o644); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	time.Sleep(testSettle)
+	assert.Equal(t, int32(1), changes.Load())
+}